@@ -0,0 +1,150 @@
+// Package alert posts critical events of the bot to webhooks, deduplicated and rate limited.
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	HEDGEFAILED  = "hedge_failed"
+	BIDREVERTED  = "bid_reverted"
+	LOWBALANCE   = "low_balance"
+	LOWGAS       = "low_gas"
+	UNREACHABLE  = "unreachable"
+	UNHEDGED     = "unhedged"
+	maxPerMinute = 10
+)
+
+// Alert is a critical event. Alerts of the same kind and key are the same condition, repeated ones are dropped.
+type Alert struct {
+	Kind    string    `json:"kind"`
+	Key     string    `json:"key"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type Sink interface {
+	Send(alert *Alert) error
+}
+
+type webhook struct {
+	url   string
+	slack bool
+	http  *http.Client
+}
+
+// NewWebhook posts the alerts to url, as {"text": ...} for slack compatible webhooks or as the json of the Alert.
+func NewWebhook(url string, slack bool) Sink {
+	return &webhook{url, slack, &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *webhook) Send(alert *Alert) (err error) {
+	var body []byte
+	if w.slack {
+		body, err = json.Marshal(map[string]string{"text": fmt.Sprintf("[auctionBidder] %s: %s", alert.Kind, alert.Message)})
+	} else {
+		body, err = json.Marshal(alert)
+	}
+	if err != nil {
+		return
+	}
+	resp, err := w.http.Post(w.url, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		err = fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return
+}
+
+// Alerter sends an alert once, and again after the interval if it is still firing. At most maxPerMinute alerts
+// are sent per minute, the dropped ones are counted in the next alert sent.
+type Alerter struct {
+	sinks      []Sink
+	mu         sync.Mutex
+	interval   time.Duration
+	firing     map[string]time.Time // kind/key -> last sent
+	sent       []time.Time          // in the last minute
+	suppressed int
+	now        func() time.Time
+}
+
+func NewAlerter(sinks []Sink, interval time.Duration) *Alerter {
+	return &Alerter{
+		sinks:    sinks,
+		interval: interval,
+		firing:   map[string]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// SetInterval applies a reloaded ALERT_INTERVAL.
+func (a *Alerter) SetInterval(interval time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.interval = interval
+}
+
+// Fire logs the alert and posts it to the webhooks in the background unless it is a duplicate or rate limited.
+func (a *Alerter) Fire(kind string, key string, format string, args ...interface{}) (sent bool) {
+	alert := a.take(kind, key, fmt.Sprintf(format, args...))
+	if alert == nil {
+		return false
+	}
+	logrus.Errorf("alert %s: %s", alert.Kind, alert.Message)
+	for _, sink := range a.sinks {
+		go func(sink Sink) {
+			if err := sink.Send(alert); err != nil {
+				logrus.Errorf("send alert %s failed: %s", alert.Kind, err.Error())
+			}
+		}(sink)
+	}
+	return true
+}
+
+// Resolve marks the condition as over, it is alerted right away if it happens again.
+func (a *Alerter) Resolve(kind string, key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.firing, kind+"/"+key)
+}
+
+// the alert to send, nil if it is dropped
+func (a *Alerter) take(kind string, key string, message string) *Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	id := kind + "/" + key
+	if last, ok := a.firing[id]; ok && now.Sub(last) < a.interval {
+		return nil
+	}
+
+	recent := a.sent[:0]
+	for _, t := range a.sent {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	a.sent = recent
+	if len(a.sent) >= maxPerMinute {
+		// not marked as firing, so it is sent once the rate allows
+		a.suppressed++
+		return nil
+	}
+
+	a.firing[id] = now
+	a.sent = append(a.sent, now)
+	if a.suppressed > 0 {
+		message = fmt.Sprintf("%s (%d more alerts dropped by the rate limit, see the log)", message, a.suppressed)
+		a.suppressed = 0
+	}
+	return &Alert{kind, key, message, now}
+}
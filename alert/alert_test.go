@@ -0,0 +1,47 @@
+package alert
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAlerter(t *testing.T) {
+	now := time.Date(2019, 10, 19, 0, 0, 0, 0, time.UTC)
+	a := NewAlerter(nil, time.Hour)
+	a.now = func() time.Time { return now }
+
+	if !a.Fire(LOWGAS, "0x1", "low") {
+		t.Errorf("first alert not sent")
+	}
+	if a.Fire(LOWGAS, "0x1", "low") {
+		t.Errorf("duplicate alert sent")
+	}
+	if !a.Fire(LOWGAS, "0x2", "low") {
+		t.Errorf("alert of another key not sent")
+	}
+
+	// repeated after the interval, or right away once resolved
+	now = now.Add(time.Hour)
+	if !a.Fire(LOWGAS, "0x1", "low") {
+		t.Errorf("alert still firing not repeated")
+	}
+	a.Resolve(LOWGAS, "0x1")
+	if !a.Fire(LOWGAS, "0x1", "low") {
+		t.Errorf("alert not sent after resolved")
+	}
+
+	// rate limited, the dropped alerts are counted in the next one
+	now = now.Add(time.Minute)
+	for i := 0; i < maxPerMinute; i++ {
+		a.Fire(BIDREVERTED, fmt.Sprint(i), "reverted")
+	}
+	if a.Fire(HEDGEFAILED, "0xa", "failed") {
+		t.Errorf("alert over the rate limit sent")
+	}
+	now = now.Add(time.Minute)
+	alert := a.take(HEDGEFAILED, "0xa", "failed")
+	if alert == nil || alert.Message != "failed (1 more alerts dropped by the rate limit, see the log)" {
+		t.Errorf("unexpected alert %v", alert)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"auctionBidder/cli"
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/history"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const backtestUsage = `usage: main backtest --blocks blocks.jsonl.gz[,more files] [--balance USDT=10000,DAI=10000] [--gas-price 10] [--gas-used 200000] [--fee-rate 0.001] [--verbose] [-- config flags]
+       main backtest fetch --from 8700000 --to 8710000 [--step 1] --output blocks.jsonl.gz [-- config flags]`
+
+// replay the strategy on recorded blocks, or fetch the auctions of past blocks from the chain
+func runBacktest(args []string) (err error) {
+	if len(args) > 0 && args[0] == "fetch" {
+		return runFetch(args[1:])
+	}
+
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	files := fs.String("blocks", "", "block files recorded by the bot or fetched, separated by commas, with wildcards")
+	balances := fs.String("balance", "", "free balance of each debt asset, e.g. USDT=10000,DAI=10000, unlimited if not set")
+	gasPrice := fs.Int64("gas-price", 10, "gas price in gwei of the blocks recorded without one")
+	gasUsed := fs.Int64("gas-used", 200000, "gas used by a bid")
+	feeRate := fs.String("fee-rate", "0.001", "ddex taker fee rate of the hedge orders")
+	verbose := fs.Bool("verbose", false, "log every decision like the bot")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if *files == "" {
+		return errors.New(backtestUsage)
+	}
+
+	params := &cli.BacktestParams{
+		Balances:     map[string]decimal.Decimal{},
+		GasPriceGwei: *gasPrice,
+		GasUsed:      *gasUsed,
+	}
+	if params.FeeRate, err = decimal.NewFromString(*feeRate); err != nil {
+		return fmt.Errorf("invalid --fee-rate %q", *feeRate)
+	}
+	for _, balance := range strings.Split(*balances, ",") {
+		if balance = strings.TrimSpace(balance); balance == "" {
+			continue
+		}
+		pair := strings.SplitN(balance, "=", 2)
+		var amount decimal.Decimal
+		if len(pair) == 2 {
+			amount, err = decimal.NewFromString(pair[1])
+		}
+		if len(pair) != 2 || err != nil {
+			return fmt.Errorf("invalid --balance %q, use SYMBOL=amount", balance)
+		}
+		params.Balances[strings.ToUpper(pair[0])] = amount
+	}
+
+	cfg, err := config.LoadReadOnly(fs.Args())
+	if err != nil {
+		return
+	}
+	var paths []string
+	for _, pattern := range strings.Split(*files, ",") {
+		var matches []string
+		if matches, err = filepath.Glob(pattern); err != nil {
+			return fmt.Errorf("invalid --blocks %q: %s", pattern, err.Error())
+		}
+		paths = append(paths, matches...)
+	}
+	blocks, err := history.Read(paths...)
+	if err != nil {
+		return
+	}
+	if len(blocks) == 0 {
+		return errors.New("no blocks recorded")
+	}
+	logrus.Infof("backtest %s from block %d to %d", strings.Join(cfg.Markets, ","), blocks[0].Number, blocks[len(blocks)-1].Number)
+	if !*verbose {
+		logrus.SetLevel(logrus.ErrorLevel)
+	}
+	report := cli.Backtest(cfg, blocks, params)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "blocks with auctions\t%d\t\n", report.Blocks)
+	fmt.Fprintf(w, "auctions seen\t%d\t\n", report.Decisions)
+	var reasons []string
+	for reason := range report.Skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  skipped %s\t%d\t\n", reason, report.Skipped[reason])
+	}
+	fmt.Fprintf(w, "bids\t%d\t\n", report.Bids)
+	fmt.Fprintf(w, "won\t%d\t\n", report.Won)
+	fmt.Fprintf(w, "win rate\t%s%%\t\n", report.WinRate().Mul(decimal.New(100, 0)).StringFixed(1))
+	fmt.Fprintf(w, "debt repaid\t%s\t\n", usd(report.RepaidUSD))
+	fmt.Fprintf(w, "peak capital in a block\t%s\t\n", usd(report.PeakCapitalUSD))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "period\ttrades\treverted\tgross\tfees\tgas\trealized\t")
+	for _, p := range report.Pnl.Periods {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", p.Label, p.Trades, p.Reverted, usd(p.GrossUSD), usd(p.FeesUSD), usd(p.GasUSD), usd(p.RealizedUSD))
+	}
+	fmt.Fprintf(w, "total\t%d\t\t%s\t%s\t%s\t%s\t\n", len(report.Pnl.Trades), usd(report.Pnl.GrossUSD), usd(report.Pnl.FeesUSD), usd(report.Pnl.GasUSD), usd(report.Pnl.RealizedUSD))
+	for _, p := range report.Pnl.Positions {
+		fmt.Fprintf(w, "open %s\t%s\t\t%s\t\t\t%s\t\n", p.Symbol, p.Amount.String(), usd(p.CostUSD), usd(p.UnrealizedUSD))
+	}
+	return w.Flush()
+}
+
+// read the auctions of past blocks from the hydro contract, the node must have their state
+func runFetch(args []string) (err error) {
+	fs := flag.NewFlagSet("backtest fetch", flag.ContinueOnError)
+	from := fs.Int64("from", 0, "first block")
+	to := fs.Int64("to", 0, "last block, inclusive")
+	step := fs.Int64("step", 1, "read every step blocks, blocks with fills are always read")
+	output := fs.String("output", "", "file to append the blocks to, gzipped if it ends with .gz")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if *from <= 0 || *to < *from || *step <= 0 || *output == "" {
+		return errors.New(backtestUsage)
+	}
+
+	cfg, err := config.LoadReadOnly(fs.Args())
+	if err != nil {
+		return
+	}
+	cfg.Export()
+
+	// no signer, the clients only read
+	ddexClient, err := client.NewDdexClient(nil)
+	if err != nil {
+		return
+	}
+	bidderClient, err := client.NewBidderClient(nil, ddexClient.Assets, ddexClient.Markets)
+	if err != nil {
+		return
+	}
+	w, err := history.NewWriter(*output)
+	if err != nil {
+		return
+	}
+	count, err := history.Fetch(bidderClient, *from, *to, *step, w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	logrus.Infof("fetched %d blocks to %s", count, *output)
+	return
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+// Account bids with its own balances and nonces, and hedges the collateral it receives.
+type Account struct {
+	Name           string
+	BidderClient   *client.BidderClient
+	DdexClient     *client.DdexClient
+	MaxBidValueUSD decimal.Decimal // zero means no limit
+}
+
+// allocate splits the debt to repay among accounts which can repay up to capacity[i] each.
+// The account with the most capacity takes the whole debt if it can, otherwise the debt is split,
+// largest capacity first. Parts not greater than minPart are dropped, they are too small to hedge.
+func allocate(capacity []decimal.Decimal, debt decimal.Decimal, minPart decimal.Decimal) (parts []decimal.Decimal) {
+	order := make([]int, len(capacity))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return capacity[order[i]].GreaterThan(capacity[order[j]])
+	})
+
+	parts = make([]decimal.Decimal, len(capacity))
+	for _, i := range order {
+		if !debt.IsPositive() {
+			break
+		}
+		part := decimal.Min(capacity[i], debt)
+		if part.LessThanOrEqual(minPart) {
+			continue
+		}
+		parts[i] = part
+		debt = debt.Sub(part)
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	d := func(values ...int64) (decimals []decimal.Decimal) {
+		for _, value := range values {
+			decimals = append(decimals, decimal.New(value, 0))
+		}
+		return
+	}
+	for _, c := range []struct {
+		capacity []decimal.Decimal
+		debt     int64
+		minPart  int64
+		expect   []decimal.Decimal
+	}{
+		{d(50, 200, 120), 100, 0, d(0, 100, 0)}, // one account is enough
+		{d(50, 80, 30), 100, 0, d(20, 80, 0)},   // split, largest first
+		{d(50, 80, 30), 200, 0, d(50, 80, 30)},  // not enough in total
+		{d(50, 80, 5), 200, 10, d(50, 80, 0)},   // too small to hedge
+		{d(0, 0), 100, 0, d(0, 0)},              // no balance
+		{d(100, 100), 100, 0, d(100, 0)},        // tie goes to the first account
+	} {
+		parts := allocate(c.capacity, decimal.New(c.debt, 0), decimal.New(c.minPart, 0))
+		for i := range parts {
+			if !parts[i].Equal(c.expect[i]) {
+				t.Errorf("allocate %v of %d: got %v, expect %v", c.capacity, c.debt, parts, c.expect)
+				break
+			}
+		}
+	}
+}
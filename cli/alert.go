@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"auctionBidder/alert"
+	"auctionBidder/config"
+	"auctionBidder/pnl"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"time"
+)
+
+// a block is expected at least this often, a node without new blocks is counted as failing
+const blockTime = 15 * time.Second
+
+func newAlerter(cfg *config.Config) *alert.Alerter {
+	var sinks []alert.Sink
+	for _, webhook := range cfg.AlertWebhooks {
+		sinks = append(sinks, alert.NewWebhook(webhook.Url, webhook.Format == config.WEBHOOKSLACK))
+	}
+	return alert.NewAlerter(sinks, cfg.AlertInterval)
+}
+
+// wait for the next block, counting the node as failing for every block time without one
+func (b *BidderBot) waitBlock() (blockNum int64, ok bool) {
+	for {
+		select {
+		case <-b.stop:
+			return 0, false
+		case blockNum = <-b.BlockChannel:
+			return blockNum, true
+		case command := <-b.commands:
+			command()
+		case <-time.After(blockTime):
+			b.checkService("ethereum node", &b.nodeFailures, errors.New("no new block"))
+		}
+	}
+}
+
+// count the blocks a service failed in a row, alert once there are too many
+func (b *BidderBot) checkService(name string, failures *int64, err error) {
+	if err == nil {
+		if *failures >= b.Config.AlertUnreachableBlocks {
+			logrus.Infof("%s is reachable again", name)
+		}
+		*failures = 0
+		b.Alerter.Resolve(alert.UNREACHABLE, name)
+		return
+	}
+	*failures++
+	if *failures >= b.Config.AlertUnreachableBlocks {
+		b.Alerter.Fire(alert.UNREACHABLE, name, "%s failed for %d blocks: %s", name, *failures, err.Error())
+	}
+}
+
+// alert on the ether for gas and the free balance of the quote assets, in which auctions are usually repaid
+func (b *BidderBot) checkBalances(prices map[string]decimal.Decimal) {
+	minEth, minUSD := b.Config.AlertMinEth, b.Config.AlertMinBalanceUSD
+	for _, account := range b.Accounts {
+		address := account.DdexClient.Address
+		if minEth.IsPositive() {
+			if eth, err := account.BidderClient.EthBalance(); err != nil {
+				logrus.Errorf("get ether balance of account %s failed: %s", account.Name, err.Error())
+			} else if eth.LessThan(minEth) {
+				b.Alerter.Fire(alert.LOWGAS, address, "account %s %s has %sETH for gas, less than %sETH", account.Name, address, eth.String(), minEth.String())
+			} else {
+				b.Alerter.Resolve(alert.LOWGAS, address)
+			}
+		}
+
+		if !minUSD.IsPositive() {
+			continue
+		}
+		inventory, err := account.DdexClient.GetInventory()
+		if err != nil {
+			logrus.Errorf("get inventory of account %s failed: %s", account.Name, err.Error())
+			continue
+		}
+		for _, symbol := range b.quoteSymbols() {
+			balance, price := inventory[symbol], prices[symbol]
+			if balance == nil || !price.IsPositive() {
+				continue
+			}
+			key := address + "/" + symbol
+			if value := balance.Free.Mul(price); value.LessThan(minUSD) {
+				b.Alerter.Fire(alert.LOWBALANCE, key, "account %s %s has %s%s free (%s$), less than %s$",
+					account.Name, address, balance.Free.StringFixed(3), symbol, value.StringFixed(2), minUSD.String())
+			} else {
+				b.Alerter.Resolve(alert.LOWBALANCE, key)
+			}
+		}
+	}
+}
+
+func (b *BidderBot) quoteSymbols() (symbols []string) {
+	seen := map[string]bool{}
+	for _, tradingPair := range b.Config.Markets {
+		market, ok := b.DdexClient.Markets[tradingPair]
+		if ok && !seen[market.Quote.Symbol] {
+			seen[market.Quote.Symbol] = true
+			symbols = append(symbols, market.Quote.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return
+}
+
+// alert when the collateral not hedged is worth too much, valued at its cost if the current price is unknown
+func (b *BidderBot) checkUnhedged(report *pnl.Report) {
+	maxUSD := b.Config.AlertMaxUnhedgedUSD
+	if !maxUSD.IsPositive() {
+		return
+	}
+	var total = decimal.Zero
+	var open []string
+	for _, position := range report.Positions {
+		value := position.CostUSD
+		if position.Priced {
+			value = position.ValueUSD
+		}
+		total = total.Add(value)
+		open = append(open, fmt.Sprintf("%s%s", position.Amount.StringFixed(3), position.Symbol))
+	}
+	if total.GreaterThan(maxUSD) {
+		b.Alerter.Fire(alert.UNHEDGED, "total", "collateral worth %s$ is not hedged: %s", total.StringFixed(2), strings.Join(open, ", "))
+	} else {
+		b.Alerter.Resolve(alert.UNHEDGED, "total")
+	}
+}
@@ -0,0 +1,520 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/metrics"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxDecisions = 1000
+	// a control command waits at most this long for the bid in flight to finish
+	commandTimeout = 2 * time.Minute
+)
+
+// keep the decision of the store in memory for the api, an error to store it is only logged
+func (b *BidderBot) recordDecision(decision *storage.Decision) {
+	if decision.Action == storage.DECISIONSKIP {
+		metrics.AuctionsSkipped.Inc(decision.Market, decision.Reason)
+	}
+	if err := b.Store.InsertDecision(decision); err != nil {
+		logrus.Errorf("record decision of auction %d failed: %s", decision.AuctionID, err.Error())
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.decisions = append(b.decisions, decision)
+	if len(b.decisions) > maxDecisions {
+		b.decisions = b.decisions[len(b.decisions)-maxDecisions:]
+	}
+}
+
+// Paused reports whether bidding is paused by the api. Bids in flight are still settled.
+func (b *BidderBot) Paused() bool {
+	return atomic.LoadInt32(&b.paused) == 1
+}
+
+func (b *BidderBot) SetPaused(paused bool) {
+	var value int32
+	if paused {
+		value = 1
+	}
+	atomic.StoreInt32(&b.paused, value)
+	logrus.Infof("bidding paused: %t", paused)
+}
+
+// run f between blocks on the bot goroutine, so it never overlaps a bid or a hedge
+func (b *BidderBot) do(f func()) error {
+	done := make(chan struct{})
+	select {
+	case b.commands <- func() { defer close(done); f() }:
+	case <-b.stop:
+		return errors.New("bot stopped")
+	case <-time.After(commandTimeout):
+		return errors.New("bot is busy, try again later")
+	}
+	<-done
+	return nil
+}
+
+func (b *BidderBot) config() *config.Config {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.Config
+}
+
+// ServeApi serves the json api at addr, it only returns on error. Control endpoints need the bearer token,
+// they are disabled if it is empty.
+func (b *BidderBot) ServeApi(addr string, token string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auctions", apiHandler("GET", "", b.apiAuctions))
+	mux.HandleFunc("/inventory", apiHandler("GET", "", b.apiInventory))
+	mux.HandleFunc("/positions", apiHandler("GET", "", b.apiPositions))
+	mux.HandleFunc("/decisions", apiHandler("GET", "", b.apiDecisions))
+	mux.HandleFunc("/config", apiHandler("GET", "", b.apiConfig))
+	mux.HandleFunc("/pause", apiHandler("POST", token, b.apiPause))
+	mux.HandleFunc("/resume", apiHandler("POST", token, b.apiResume))
+	mux.HandleFunc("/markets/", apiHandler("PUT,DELETE", token, b.apiMarket))
+	mux.HandleFunc("/hedge", apiHandler("POST", token, b.apiHedge))
+	mux.HandleFunc("/cancel-orders", apiHandler("POST", token, b.apiCancelOrders))
+	return http.ListenAndServe(addr, mux)
+}
+
+// apiError is returned to the client with its status, other errors are 500
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// methods are separated by commas, control endpoints have a token
+func apiHandler(methods string, token string, handle func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	control := strings.Contains(methods, "POST") || strings.Contains(methods, "PUT") || strings.Contains(methods, "DELETE")
+	return func(w http.ResponseWriter, r *http.Request) {
+		var result interface{}
+		var err error
+		if !strings.Contains(","+methods+",", ","+r.Method+",") {
+			err = &apiError{http.StatusMethodNotAllowed, fmt.Sprintf("use %s", methods)}
+		} else if control && token == "" {
+			err = &apiError{http.StatusForbidden, "control endpoints are disabled, set API_TOKEN to enable them"}
+		} else if control && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			err = &apiError{http.StatusUnauthorized, "invalid token"}
+		} else {
+			result, err = handle(r)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			status := http.StatusInternalServerError
+			if e, ok := err.(*apiError); ok {
+				status = e.status
+			}
+			if control || status == http.StatusInternalServerError {
+				logrus.Errorf("api %s %s failed: %s", r.Method, r.URL.Path, err.Error())
+			}
+			w.WriteHeader(status)
+			result = map[string]string{"error": err.Error()}
+		} else if control {
+			logrus.Infof("api %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+type apiAuction struct {
+	ID                  int64           `json:"id"`
+	Market              string          `json:"market"`
+	DebtSymbol          string          `json:"debtSymbol"`
+	CollateralSymbol    string          `json:"collateralSymbol"`
+	AvailableDebt       decimal.Decimal `json:"availableDebt"`
+	AvailableCollateral decimal.Decimal `json:"availableCollateral"`
+	Ratio               decimal.Decimal `json:"ratio"`
+	Price               decimal.Decimal `json:"price"`
+}
+
+// the auctions of the latest block
+func (b *BidderBot) apiAuctions(r *http.Request) (interface{}, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var auctions = []*apiAuction{}
+	for _, auction := range b.auctions {
+		auctions = append(auctions, &apiAuction{
+			auction.ID,
+			auction.TradingPair,
+			auction.DebtSymbol,
+			auction.CollateralSymbol,
+			auction.AvailableDebt,
+			auction.AvailableCollateral,
+			auction.Ratio,
+			auction.Price,
+		})
+	}
+	return auctions, nil
+}
+
+type apiBalance struct {
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+	Total  decimal.Decimal `json:"total"`
+}
+
+type apiInventory struct {
+	Account  string                 `json:"account"`
+	Address  string                 `json:"address"`
+	Balances map[string]*apiBalance `json:"balances"`
+}
+
+func (b *BidderBot) apiInventory(r *http.Request) (interface{}, error) {
+	var inventories []*apiInventory
+	for _, account := range b.Accounts {
+		inventory, err := account.DdexClient.GetInventory()
+		if err != nil {
+			return nil, errors.Wrapf(err, "get inventory of account %s failed", account.Name)
+		}
+		balances := map[string]*apiBalance{}
+		for symbol, balance := range inventory {
+			balances[symbol] = &apiBalance{balance.Free, balance.Lock, balance.Total}
+		}
+		inventories = append(inventories, &apiInventory{account.Name, account.DdexClient.Address, balances})
+	}
+	return inventories, nil
+}
+
+type apiPosition struct {
+	Symbol        string          `json:"symbol"`
+	Amount        decimal.Decimal `json:"amount"`
+	CostUSD       decimal.Decimal `json:"costUSD"`
+	ValueUSD      decimal.Decimal `json:"valueUSD"`
+	UnrealizedUSD decimal.Decimal `json:"unrealizedUSD"`
+	Priced        bool            `json:"priced"`
+}
+
+type apiPositions struct {
+	Positions     []*apiPosition  `json:"positions"`
+	RealizedUSD   decimal.Decimal `json:"realizedUSD"`
+	UnrealizedUSD decimal.Decimal `json:"unrealizedUSD"`
+	GasUSD        decimal.Decimal `json:"gasUSD"`
+	FeesUSD       decimal.Decimal `json:"feesUSD"`
+}
+
+// collateral not hedged and the cumulative pnl of the ledger
+func (b *BidderBot) apiPositions(r *http.Request) (interface{}, error) {
+	trades, err := b.Store.QueryTrades(storage.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	prices, err := client.GetAssetUSDPrices(b.config().DdexUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "get usd prices failed")
+	}
+	report := pnl.Compute(trades, prices, pnl.DAILY)
+	var positions = []*apiPosition{}
+	for _, p := range report.Positions {
+		positions = append(positions, &apiPosition{p.Symbol, p.Amount, p.CostUSD, p.ValueUSD, p.UnrealizedUSD, p.Priced})
+	}
+	return &apiPositions{positions, report.RealizedUSD, report.UnrealizedUSD, report.GasUSD, report.FeesUSD}, nil
+}
+
+type apiDecision struct {
+	Time                time.Time       `json:"time"`
+	Block               int64           `json:"block"`
+	AuctionID           int64           `json:"auctionId"`
+	Market              string          `json:"market"`
+	Action              string          `json:"action"` // bid or skip
+	Reason              string          `json:"reason"`
+	Error               string          `json:"error,omitempty"`
+	AuctionPrice        decimal.Decimal `json:"auctionPrice"`
+	Ratio               decimal.Decimal `json:"ratio"`
+	AvailableDebt       decimal.Decimal `json:"availableDebt"`
+	AvailableCollateral decimal.Decimal `json:"availableCollateral"`
+	FreeDebt            decimal.Decimal `json:"freeDebt"`
+	CollateralPriceUSD  decimal.Decimal `json:"collateralPriceUSD"`
+	RepayDebt           decimal.Decimal `json:"repayDebt"`
+	Collateral          decimal.Decimal `json:"collateral"`
+	ExpectedReceiveDebt decimal.Decimal `json:"expectedReceiveDebt"`
+	MinReceiveDebt      decimal.Decimal `json:"minReceiveDebt"`
+	ProfitMargin        decimal.Decimal `json:"profitMargin"`
+	GasPriceGwei        int64           `json:"gasPriceGwei"`
+}
+
+// most recent first, ?limit=n
+func (b *BidderBot) apiDecisions(r *http.Request) (interface{}, error) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			return nil, badRequest("invalid limit %q", value)
+		}
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var decisions = []*apiDecision{}
+	for i := len(b.decisions) - 1; i >= 0 && len(decisions) < limit; i-- {
+		d := b.decisions[i]
+		decisions = append(decisions, &apiDecision{
+			time.Unix(0, d.CreatedAt*int64(time.Millisecond)).UTC(),
+			d.BlockNumber,
+			d.AuctionID,
+			d.Market,
+			d.Action,
+			d.Reason,
+			d.Error,
+			d.AuctionPrice,
+			d.Ratio,
+			d.AvailableDebt,
+			d.AvailableCollateral,
+			d.FreeDebt,
+			d.CollateralPriceUSD,
+			d.RepayDebt,
+			d.Collateral,
+			d.ExpectedReceiveDebt,
+			d.MinReceiveDebt,
+			d.ProfitMargin,
+			d.GasPriceGwei,
+		})
+	}
+	return decisions, nil
+}
+
+type apiMarket struct {
+	Market           string          `json:"market"`
+	Enabled          bool            `json:"enabled"`
+	ProfitMargin     decimal.Decimal `json:"profitMargin"`
+	MaxSlippage      decimal.Decimal `json:"maxSlippage"`
+	MinOrderValueUSD decimal.Decimal `json:"minOrderValueUSD"`
+	MaxBidValueUSD   decimal.Decimal `json:"maxBidValueUSD"`
+	GasPriceLevel    string          `json:"gasPriceLevel"`
+	ApiOverride      bool            `json:"apiOverride"` // params set by the api, config.json is ignored for the market until the restart
+}
+
+type apiConfig struct {
+	Paused  bool            `json:"paused"`
+	Params  json.RawMessage `json:"params"` // secrets excluded
+	Markets []*apiMarket    `json:"markets"`
+}
+
+func (b *BidderBot) apiConfig(r *http.Request) (interface{}, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	cfg := b.Config
+	var markets = []*apiMarket{}
+	for _, tradingPair := range cfg.Markets {
+		m, _ := cfg.Market(tradingPair)
+		_, overridden := b.overrides[tradingPair]
+		markets = append(markets, &apiMarket{
+			m.TradingPair,
+			m.Enabled,
+			m.ProfitMargin,
+			m.MaxSlippage,
+			m.MinOrderValueUSD,
+			m.MaxBidValueUSD,
+			m.GasPriceLevel,
+			overridden,
+		})
+	}
+	return &apiConfig{b.Paused(), json.RawMessage(cfg.Snapshot()), markets}, nil
+}
+
+func (b *BidderBot) apiPause(r *http.Request) (interface{}, error) {
+	b.SetPaused(true)
+	return map[string]bool{"paused": true}, nil
+}
+
+func (b *BidderBot) apiResume(r *http.Request) (interface{}, error) {
+	b.SetPaused(false)
+	return map[string]bool{"paused": false}, nil
+}
+
+// PUT /markets/ETH-USDT replaces the overrides of the market with the MARKET_PARAMS object in the body,
+// DELETE removes them. A PUT is kept over edits of config.json until the restart, the market has apiOverride set.
+// After a DELETE the next version of config.json applies to the market again.
+func (b *BidderBot) apiMarket(r *http.Request) (interface{}, error) {
+	tradingPair := strings.TrimPrefix(r.URL.Path, "/markets/")
+	var params *config.MarketParams
+	if r.Method == "PUT" {
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&params); err != nil || params == nil {
+			return nil, badRequest("body must be a MARKET_PARAMS object: %v", err)
+		}
+	}
+
+	var err error
+	if doErr := b.do(func() {
+		var next *config.Config
+		if next, err = b.Config.WithMarketParams(tradingPair, params); err != nil {
+			err = badRequest(err.Error())
+			return
+		}
+		if err = b.applyConfig(next); err != nil {
+			return
+		}
+		b.mu.Lock()
+		if b.overrides == nil {
+			b.overrides = map[string]*config.MarketParams{}
+		}
+		if params != nil {
+			b.overrides[tradingPair] = params
+		} else {
+			delete(b.overrides, tradingPair)
+		}
+		b.mu.Unlock()
+	}); doErr != nil {
+		return nil, &apiError{http.StatusServiceUnavailable, doErr.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.apiConfig(r)
+}
+
+type hedgeRequest struct {
+	BidID   int64           `json:"bidId"` // hedge the open collateral of the bid and record the order in the ledger
+	Account string          `json:"account"`
+	Market  string          `json:"market"`
+	Symbol  string          `json:"symbol"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+type hedgeResponse struct {
+	OrderID  string          `json:"orderId"`
+	Sold     decimal.Decimal `json:"sold"`
+	Received decimal.Decimal `json:"received"`
+	Recorded bool            `json:"recorded"`
+}
+
+// sell an asset at a market order now, either the open collateral of a bid or any amount of an account
+func (b *BidderBot) apiHedge(r *http.Request) (interface{}, error) {
+	var req hedgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, badRequest("invalid body: %s", err.Error())
+	}
+
+	var res *hedgeResponse
+	var err error
+	if doErr := b.do(func() { res, err = b.forceHedge(&req) }); doErr != nil {
+		return nil, &apiError{http.StatusServiceUnavailable, doErr.Error()}
+	}
+	return res, err
+}
+
+func (b *BidderBot) forceHedge(req *hedgeRequest) (res *hedgeResponse, err error) {
+	var account *Account
+	var trade *storage.Trade
+	if req.BidID > 0 {
+		var trades []*storage.Trade
+		if trades, err = b.Store.QueryTrades(storage.Filter{}); err != nil {
+			return
+		}
+		for _, t := range trades {
+			if t.Bid.ID == req.BidID {
+				trade = t
+			}
+		}
+		var computed *pnl.Trade
+		if trade != nil {
+			computed = pnl.ComputeTrade(trade)
+		}
+		if computed == nil || !computed.OpenAmount.IsPositive() {
+			return nil, badRequest("bid %d has no open collateral", req.BidID)
+		}
+		// bids recorded before accounts were stored belong to the first account
+		account = b.Accounts[0]
+		if trade.Bid.Account != "" {
+			account = b.account(trade.Bid.Account)
+		}
+		req.Market, req.Symbol = trade.Bid.Market, trade.Bid.CollateralSymbol
+		if !req.Amount.IsPositive() || req.Amount.GreaterThan(computed.OpenAmount) {
+			req.Amount = computed.OpenAmount
+		}
+	} else {
+		for _, a := range b.Accounts {
+			if a.Name == req.Account || (req.Account == "" && a == b.Accounts[0]) {
+				account = a
+			}
+		}
+		if req.Market == "" || req.Symbol == "" || !req.Amount.IsPositive() {
+			return nil, badRequest("set bidId, or market, symbol and a positive amount")
+		}
+	}
+	if account == nil {
+		return nil, badRequest("unknown account")
+	}
+	if _, ok := account.DdexClient.Markets[req.Market]; !ok {
+		return nil, badRequest("unknown market %s", req.Market)
+	}
+
+	slippage := b.Config.MaxSlippage
+	if market, ok := b.Config.Market(req.Market); ok {
+		slippage = market.MaxSlippage
+	}
+	logrus.Infof("account %s force hedge %s%s at %s", account.Name, req.Amount.String(), req.Symbol, req.Market)
+	order, sold, received, err := account.DdexClient.MarketSellAsset(req.Market, req.Symbol, req.Amount, slippage)
+	if err != nil {
+		return
+	}
+	res = &hedgeResponse{OrderID: order.Id, Sold: sold, Received: received}
+	if trade != nil {
+		if err = b.Store.InsertHedgeOrder(newHedgeOrder(trade.Bid.ID, req.Market, order, sold, received)); err != nil {
+			return nil, errors.Wrapf(err, "order %s placed but not recorded", order.Id)
+		}
+		res.Recorded = true
+		b.updatePnlView()
+	}
+	return
+}
+
+// cancel the pending ddex orders of an account, or of every account if none is given
+func (b *BidderBot) apiCancelOrders(r *http.Request) (interface{}, error) {
+	var req struct {
+		Account string `json:"account"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, badRequest("invalid body: %s", err.Error())
+		}
+	}
+
+	var canceled = []string{}
+	var err error
+	if doErr := b.do(func() {
+		for _, account := range b.Accounts {
+			if req.Account != "" && account.Name != req.Account {
+				continue
+			}
+			if err = account.DdexClient.CancelAllPendingOrders(); err != nil {
+				err = errors.Wrapf(err, "cancel orders of account %s failed", account.Name)
+				return
+			}
+			canceled = append(canceled, account.Name)
+		}
+	}); doErr != nil {
+		return nil, &apiError{http.StatusServiceUnavailable, doErr.Error()}
+	}
+	if err == nil && len(canceled) == 0 {
+		err = badRequest("unknown account %s", req.Account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]string{"canceled": canceled}, nil
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"auctionBidder/config"
+	"auctionBidder/simulator"
+	"auctionBidder/storage"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApi(t *testing.T) {
+	b := &BidderBot{Store: storage.NewMemoryStore()}
+	for i := int64(1); i <= 3; i++ {
+		b.recordDecision(&storage.Decision{BlockNumber: 100 + i, AuctionID: i, Market: "ETH-USDT", Action: storage.DECISIONSKIP, Reason: "not_profitable"})
+	}
+	if stored, _ := b.Store.QueryDecisions(storage.Filter{}); len(stored) != 3 {
+		t.Errorf("decisions not stored: %d", len(stored))
+	}
+
+	request := func(handler http.HandlerFunc, method string, path string, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	w := request(apiHandler("GET", "", b.apiDecisions), "GET", "/decisions?limit=2", "")
+	var decisions []*apiDecision
+	json.Unmarshal(w.Body.Bytes(), &decisions)
+	if w.Code != http.StatusOK || len(decisions) != 2 || decisions[0].AuctionID != 3 || decisions[0].Block != 103 {
+		t.Errorf("unexpected decisions %d %s", w.Code, w.Body.String())
+	}
+	if w = request(apiHandler("GET", "", b.apiDecisions), "GET", "/decisions?limit=x", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit should be rejected, got %d", w.Code)
+	}
+
+	pause := apiHandler("POST", "secret", b.apiPause)
+	for _, c := range []struct {
+		handler http.HandlerFunc
+		method  string
+		token   string
+		status  int
+	}{
+		{pause, "GET", "secret", http.StatusMethodNotAllowed},
+		{pause, "POST", "", http.StatusUnauthorized},
+		{pause, "POST", "wrong", http.StatusUnauthorized},
+		{apiHandler("POST", "", b.apiPause), "POST", "", http.StatusForbidden},
+	} {
+		if w = request(c.handler, c.method, "/pause", c.token); w.Code != c.status {
+			t.Errorf("%s with token %q: expected %d, got %d", c.method, c.token, c.status, w.Code)
+		}
+	}
+	if b.Paused() {
+		t.Errorf("rejected requests should not pause the bot")
+	}
+
+	if w = request(pause, "POST", "/pause", "secret"); w.Code != http.StatusOK || !b.Paused() {
+		t.Errorf("bot not paused: %d %s", w.Code, w.Body.String())
+	}
+	request(apiHandler("POST", "secret", b.apiResume), "POST", "/resume", "secret")
+	if b.Paused() {
+		t.Errorf("bot not resumed")
+	}
+}
+
+func TestApiOverridesKeptOnReload(t *testing.T) {
+	disabled := false
+	b := &BidderBot{overrides: map[string]*config.MarketParams{
+		"ETH-USDT": {Enabled: &disabled},
+		"ETH-DAI":  {Enabled: &disabled},
+	}}
+	// a new config.json enabling ETH-USDT and no longer monitoring ETH-DAI
+	enabled := true
+	next := &config.Config{Markets: []string{"ETH-USDT"}, ProfitMargin: decimal.New(1, -2), MarketParams: map[string]*config.MarketParams{"ETH-USDT": {Enabled: &enabled}}}
+
+	applied := b.withOverrides(next)
+	if m, _ := applied.Market("ETH-USDT"); m == nil || m.Enabled {
+		t.Errorf("the api override of ETH-USDT should be kept: %+v", m)
+	}
+	if _, ok := b.overrides["ETH-DAI"]; ok || len(b.overrides) != 1 {
+		t.Errorf("the override of a market no longer monitored should be dropped: %+v", b.overrides)
+	}
+}
+
+func TestApiMarket(t *testing.T) {
+	d := decimal.RequireFromString
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	bot, _, _ := newTestBot(t, s)
+	go bot.Run()
+	defer bot.Stop()
+
+	handler := apiHandler("PUT,DELETE", "secret", bot.apiMarket)
+	request := func(method string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/markets/ETH-USDT", strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	if w := request("PUT", `{"ENABLED": false}`); w.Code != http.StatusOK {
+		t.Fatalf("put failed %d %s", w.Code, w.Body.String())
+	}
+	if m, _ := bot.config().Market("ETH-USDT"); m.Enabled || bot.overrides["ETH-USDT"] == nil {
+		t.Errorf("market not overridden: %+v %+v", m, bot.overrides)
+	}
+
+	if w := request("DELETE", ""); w.Code != http.StatusOK {
+		t.Fatalf("delete failed %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := bot.overrides["ETH-USDT"]; ok {
+		t.Errorf("the override should be removed: %+v", bot.overrides)
+	}
+	// config.json applies to the market again
+	enabled := true
+	next := &config.Config{Markets: []string{"ETH-USDT"}, ProfitMargin: decimal.New(1, -2), MarketParams: map[string]*config.MarketParams{"ETH-USDT": {Enabled: &enabled}}}
+	if m, _ := bot.withOverrides(next).Market("ETH-USDT"); !m.Enabled {
+		t.Errorf("config.json should apply after the delete: %+v", m)
+	}
+}
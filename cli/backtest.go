@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/history"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// the free balance of a debt asset without a backtest balance
+var unlimited = decimal.New(1, 30)
+
+// BacktestParams are the assumptions of a backtest, the strategy is configured like the bot.
+type BacktestParams struct {
+	Balances     map[string]decimal.Decimal // free balance of each debt asset, unlimited if missing
+	GasPriceGwei int64                      // of the blocks recorded without one, before the tips of the market
+	GasUsed      int64                      // of a bid
+	FeeRate      decimal.Decimal            // ddex taker fee rate of the hedge orders
+}
+
+// BacktestReport is the result of the strategy replayed on the recorded blocks.
+type BacktestReport struct {
+	Blocks         int            // with auctions
+	Decisions      int            // one per auction per block
+	Skipped        map[string]int // decisions by reason
+	Bids           int            // mined, bids still pending at the last block are dropped
+	Won            int            // bids which repaid debt, the others reverted
+	Trades         []*storage.Trade
+	Pnl            *pnl.Report
+	RepaidUSD      decimal.Decimal // debt repaid by all bids
+	PeakCapitalUSD decimal.Decimal // most debt repaid in a block
+	Balances       map[string]decimal.Decimal
+}
+
+func (r *BacktestReport) WinRate() decimal.Decimal {
+	if r.Bids == 0 {
+		return decimal.Zero
+	}
+	return decimal.New(int64(r.Won), 0).Div(decimal.New(int64(r.Bids), 0))
+}
+
+// the latest oracle prices and orderbooks recorded
+type backtestQuoter struct {
+	prices     map[string]decimal.Decimal
+	orderbooks map[string]*client.Orderbook
+}
+
+func (q *backtestQuoter) GetAssetUSDPrice(assetSymbol string) (price decimal.Decimal, err error) {
+	price, ok := q.prices[assetSymbol]
+	if !ok || !price.IsPositive() {
+		err = errors.Errorf("no usd price of %s recorded", assetSymbol)
+	}
+	return
+}
+
+func (q *backtestQuoter) QuerySellAssetReceiveAmount(tradingPair string, assetSymbol string, payAmount decimal.Decimal) (receiveAmount decimal.Decimal, err error) {
+	orderbook, ok := q.orderbooks[tradingPair]
+	if !ok {
+		return decimal.Zero, errors.Errorf("no orderbook of %s recorded", tradingPair)
+	}
+	return orderbook.SellReceiveAmount(strings.HasSuffix(tradingPair, "-"+assetSymbol), payAmount)
+}
+
+type backtestFunds struct {
+	balances map[string]decimal.Decimal
+}
+
+func (f *backtestFunds) FreeBalance(symbol string) (free decimal.Decimal, err error) {
+	if balance, ok := f.balances[symbol]; ok {
+		return balance, nil
+	}
+	return unlimited, nil
+}
+
+func (f *backtestFunds) BidLimitUSD() decimal.Decimal {
+	return decimal.Zero
+}
+
+func (f *backtestFunds) add(symbol string, amount decimal.Decimal) {
+	if balance, ok := f.balances[symbol]; ok {
+		f.balances[symbol] = balance.Add(amount)
+	}
+}
+
+// Backtest replays the strategy of the bot on the blocks. Bids are mined in the next block after the fills of
+// other bidders, at the ratio of that block, and hedged at once on the latest orderbook. The bot waits for its
+// bids, so it doesn't decide in the block they are mined in. Collateral the orderbook is too thin for stays open.
+func Backtest(cfg *config.Config, blocks []*history.Block, params *BacktestParams) (report *BacktestReport) {
+	report = &BacktestReport{
+		Skipped:        map[string]int{},
+		RepaidUSD:      decimal.Zero,
+		PeakCapitalUSD: decimal.Zero,
+		Balances:       map[string]decimal.Decimal{},
+	}
+	for symbol, balance := range params.Balances {
+		report.Balances[symbol] = balance
+	}
+	quoter := &backtestQuoter{map[string]decimal.Decimal{}, map[string]*client.Orderbook{}}
+	funds := &backtestFunds{report.Balances}
+
+	var pending []*storage.Trade
+	for _, block := range blocks {
+		// a market or a price missing in the block keeps its latest value
+		for symbol, price := range block.Prices {
+			quoter.prices[symbol] = price
+		}
+		for tradingPair, orderbook := range block.Orderbooks {
+			quoter.orderbooks[tradingPair] = orderbook
+		}
+		if block.Auctions == nil {
+			continue
+		}
+		if len(pending) > 0 {
+			capital := decimal.Zero
+			for _, trade := range pending {
+				report.settle(trade, block, quoter, funds, params)
+				capital = capital.Add(trade.Fill.RepayDebt.Mul(trade.Bid.DebtPriceUSD))
+			}
+			report.RepaidUSD = report.RepaidUSD.Add(capital)
+			report.PeakCapitalUSD = decimal.Max(report.PeakCapitalUSD, capital)
+			pending = nil
+			continue
+		}
+
+		if len(block.Auctions) > 0 {
+			report.Blocks++
+		}
+		for _, auction := range block.Auctions {
+			decision := newDecision(auction, block.Number)
+			market, parts, err := decideBid(cfg, quoter, []Funds{funds}, auction, decision)
+			report.Decisions++
+			if err != nil || decision.Action != storage.DECISIONBID {
+				report.Skipped[decision.Reason]++
+				continue
+			}
+			gasPriceInGwei := block.GasPriceGwei
+			if gasPriceInGwei == 0 {
+				gasPriceInGwei = params.GasPriceGwei
+			}
+			pending = append(pending, &storage.Trade{Bid: &storage.Bid{
+				ID:                  int64(report.Bids + len(pending) + 1),
+				CreatedAt:           block.Time * 1000,
+				AuctionID:           auction.ID,
+				Market:              auction.TradingPair,
+				DebtSymbol:          auction.DebtSymbol,
+				CollateralSymbol:    auction.CollateralSymbol,
+				BlockNumber:         block.Number,
+				AvailableDebt:       auction.AvailableDebt,
+				AvailableCollateral: auction.AvailableCollateral,
+				AuctionPrice:        auction.Price,
+				DebtPriceUSD:        quoter.prices[auction.DebtSymbol],
+				CollateralPriceUSD:  decision.CollateralPriceUSD,
+				EthPriceUSD:         quoter.prices["ETH"],
+				ExpectedReceiveDebt: decision.ExpectedReceiveDebt,
+				RepayDebt:           parts[0],
+				GasPriceGwei:        gasPriceInGwei + int64(market.GasPriceTipsGwei()),
+				Status:              storage.BIDSENT,
+			}})
+		}
+	}
+	if len(pending) > 0 {
+		logrus.Infof("%d bids pending at the last block are dropped", len(pending))
+	}
+
+	report.Pnl = pnl.Compute(report.Trades, quoter.prices, pnl.DAILY)
+	return
+}
+
+// the bid is mined in the block, it reverts if other bidders took the whole auction before
+func (r *BacktestReport) settle(trade *storage.Trade, block *history.Block, quoter *backtestQuoter, funds *backtestFunds, params *BacktestParams) {
+	bid := trade.Bid
+	gasUsed := decimal.New(params.GasUsed, 0)
+	trade.Fill = &storage.Fill{
+		BidID:             bid.ID,
+		BlockNumber:       block.Number,
+		RepayDebt:         decimal.Zero,
+		ReceiveCollateral: decimal.Zero,
+		GasUsed:           params.GasUsed,
+		GasCost:           gasUsed.Mul(decimal.New(bid.GasPriceGwei, -9)),
+	}
+	bid.Status = storage.BIDREVERTED
+	for _, auction := range block.Auctions {
+		if auction.ID == bid.AuctionID && auction.AvailableDebt.IsPositive() && auction.Price.IsPositive() {
+			trade.Fill.RepayDebt = decimal.Min(bid.RepayDebt, auction.AvailableDebt)
+			trade.Fill.ReceiveCollateral = trade.Fill.RepayDebt.Div(auction.Price)
+			bid.Status = storage.BIDFILLED
+		}
+	}
+	r.Bids++
+	r.Trades = append(r.Trades, trade)
+	if bid.Status != storage.BIDFILLED {
+		logrus.Debugf("backtest bid on auction %d reverted in block %d", bid.AuctionID, block.Number)
+		return
+	}
+	r.Won++
+	funds.add(bid.DebtSymbol, trade.Fill.RepayDebt.Neg())
+
+	collateral := trade.Fill.ReceiveCollateral
+	receive, err := quoter.QuerySellAssetReceiveAmount(bid.Market, bid.CollateralSymbol, collateral)
+	if err != nil {
+		logrus.Debugf("backtest hedge of auction %d failed, the collateral stays open: %s", bid.AuctionID, err.Error())
+		return
+	}
+	side := utils.SELL
+	if strings.HasSuffix(bid.Market, "-"+bid.CollateralSymbol) {
+		side = utils.BUY
+	}
+	trade.HedgeOrders = []*storage.HedgeOrder{{
+		BidID:         bid.ID,
+		Market:        bid.Market,
+		Side:          side,
+		Amount:        collateral,
+		SellAmount:    collateral,
+		ReceiveAmount: receive,
+		FeeRate:       params.FeeRate,
+		GasFee:        decimal.Zero,
+		Status:        storage.HEDGEFILLED,
+	}}
+	funds.add(bid.DebtSymbol, receive)
+}
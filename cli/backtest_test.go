@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"auctionBidder/config"
+	"auctionBidder/history"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestBacktest(t *testing.T) {
+	blocks, err := history.Read("testdata/backtest.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Markets:          []string{"ETH-USDT"},
+		ProfitMargin:     decimal.New(1, -2),
+		MinOrderValueUSD: decimal.New(100, 0),
+	}
+	report := Backtest(cfg, blocks, &BacktestParams{
+		Balances:     map[string]decimal.Decimal{"USDT": decimal.New(600, 0)},
+		GasPriceGwei: 10,
+		GasUsed:      200000,
+		FeeRate:      decimal.New(1, -3),
+	})
+
+	// auction 1 is half taken by another bidder before the bid is mined, auction 3 is gone
+	if report.Blocks != 3 || report.Decisions != 4 || report.Bids != 2 || report.Won != 1 || report.WinRate().String() != "0.5" {
+		t.Errorf("unexpected counts %+v", report)
+	}
+	if report.Skipped["not_monitored"] != 1 || report.Skipped["not_profitable"] != 1 {
+		t.Errorf("unexpected skips %v", report.Skipped)
+	}
+	if report.Trades[0].Fill.RepayDebt.String() != "450" || report.Trades[1].Bid.RepayDebt.String() != "647.5" {
+		t.Errorf("bids not sized by the balance: %s %s", report.Trades[0].Fill.RepayDebt, report.Trades[1].Bid.RepayDebt)
+	}
+	if report.PeakCapitalUSD.String() != "450" || report.Balances["USDT"].String() != "647.5" {
+		t.Errorf("unexpected capital %s balance %s", report.PeakCapitalUSD, report.Balances["USDT"])
+	}
+	// 2.5ETH sold at 199 for 450USDT, minus 0.1% fee and the gas of both bids
+	if report.Pnl.RealizedUSD.String() != "46.2025" {
+		t.Errorf("unexpected realized pnl %s", report.Pnl.RealizedUSD)
+	}
+}
@@ -1,39 +1,103 @@
 package cli
 
 import (
+	"auctionBidder/alert"
 	"auctionBidder/client"
-	"auctionBidder/utils"
+	"auctionBidder/config"
+	"auctionBidder/history"
+	"auctionBidder/metrics"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
 	"auctionBidder/web3"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
-	"strings"
+	"sync"
 )
 
 type BidderBot struct {
-	BidderClient     *client.BidderClient
-	DdexClient       *client.DdexClient
-	BlockChannel     chan int64
-	MaxSlippage      decimal.Decimal
-	MinOrderValueUSD decimal.Decimal
-	GasPriceTipsGwei int // send tx using gas price "fast" from ether gas station plus tips
-	Markets          string
-	ProfitMargin     decimal.Decimal
+	Accounts      []*Account
+	BidderClient  *client.BidderClient // of the first account, used to read auctions
+	DdexClient    *client.DdexClient   // of the first account, used for market data
+	BlockChannel  chan int64
+	Config        *config.Config
+	Store         storage.Store
+	Alerter       *alert.Alerter
+	Recorder      *history.Recorder     // nil unless HISTORY_DIR is set
+	ConfigChannel <-chan *config.Config // new versions of config.json, applied between blocks
+	configID      int64                 // snapshot of Config recorded with the bids
+	nodeFailures  int64                 // blocks in a row the ethereum node failed in
+	ddexFailures  int64
+	paused        int32                           // bidding paused by the api, accessed atomically
+	recording     int32                           // a block is being recorded, accessed atomically
+	commands      chan func()                     // control commands of the api, run between blocks
+	mu            sync.RWMutex                    // guards the fields read by the api: Config, overrides, auctions and decisions
+	overrides     map[string]*config.MarketParams // market params set by the api, kept over new versions of config.json
+	auctions      []*client.Auction               // of the latest block
+	decisions     []*storage.Decision             // most recent last
+	stop          chan struct{}
+	stopped       chan struct{}
+	stopOnce      sync.Once
+}
+
+func NewBidderBot(
+	accounts []*Account,
+	blockChannel chan int64,
+	cfg *config.Config,
+	store storage.Store,
+	configChannel <-chan *config.Config,
+) *BidderBot {
+	return &BidderBot{
+		Accounts:      accounts,
+		BidderClient:  accounts[0].BidderClient,
+		DdexClient:    accounts[0].DdexClient,
+		BlockChannel:  blockChannel,
+		Config:        cfg,
+		Store:         store,
+		Alerter:       newAlerter(cfg),
+		ConfigChannel: configChannel,
+		commands:      make(chan func()),
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
 }
 
 func (b *BidderBot) Run() {
+	defer close(b.stopped)
+	b.saveConfigSnapshot()
 	b.updatePnlView()
 	for true {
-		UpdateInventoryView(b.DdexClient)
-		blockNum := <-b.BlockChannel
+		// balances are only fetched for the gui or the metrics
+		if DefaultGui != nil || b.Config.MetricsAddr != "" {
+			UpdateInventoryView(b.Accounts)
+		}
+		blockNum, ok := b.waitBlock()
+		if !ok {
+			logrus.Info("bidder bot stopped")
+			return
+		}
 		logrus.Infof("new block %d", blockNum)
+		metrics.ProcessedBlock.Set(float64(blockNum))
+		b.reloadConfig()
+		prices, err := client.GetAssetUSDPrices(b.Config.DdexUrl)
+		b.checkService("ddex", &b.ddexFailures, err)
+		b.checkBalances(prices)
 		allAuctions, err := b.BidderClient.GetAllAuctions()
+		b.checkService("ethereum node", &b.nodeFailures, err)
 		if err != nil {
 			continue
 		}
 		UpdateAuctionView(allAuctions)
+		b.record(blockNum, allAuctions, prices)
+		b.mu.Lock()
+		b.auctions = allAuctions
+		b.mu.Unlock()
 		for _, auction := range allAuctions {
-			err := b.tryFillAuction(auction)
+			if b.stopping() {
+				break
+			}
+			metrics.AuctionsSeen.Inc(auction.TradingPair)
+			err := b.tryFillAuction(auction, blockNum)
 			if err != nil {
 				logrus.Errorf("try fill auction #%d failed: %s", auction.ID, err.Error())
 			}
@@ -41,65 +105,243 @@ func (b *BidderBot) Run() {
 	}
 }
 
-func (b *BidderBot) tryFillAuction(auction *client.Auction) (err error) {
-	// check if the market is under monitor
-	if !strings.Contains(b.Markets, auction.TradingPair) {
-		logrus.Debugf("auction trading pair %s is not in monitor list %s", auction.TradingPair, b.Markets)
-		return nil
-	}
-	logrus.Debugf("try fill auction %d", auction.ID)
+// Stop asks the bot to quit and blocks until the bid and hedge in flight, if any, are finished.
+func (b *BidderBot) Stop() {
+	b.stopOnce.Do(func() {
+		logrus.Info("waiting for in-flight bids and hedges to finish")
+		close(b.stop)
+	})
+	<-b.stopped
+}
 
-	// truncate order size by free balance
-	inventory, err := b.DdexClient.GetInventory()
-	if err != nil {
+// apply a pending config change, if any, before handling the block
+func (b *BidderBot) reloadConfig() {
+	var next *config.Config
+	select {
+	case next = <-b.ConfigChannel:
+	default:
 		return
 	}
-	freeBalance := inventory[auction.DebtSymbol].Free
-	if freeBalance.IsZero() {
-		err = errors.Errorf(`%s balance is zero`, auction.DebtSymbol)
-		return
+	if err := b.applyConfig(b.withOverrides(next)); err != nil {
+		logrus.Errorf("reject new config: %s", err.Error())
+	}
+}
+
+// the market params set by the api stay on top of new versions of config.json until the restart
+func (b *BidderBot) withOverrides(next *config.Config) *config.Config {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for tradingPair, params := range b.overrides {
+		overridden, err := next.WithMarketParams(tradingPair, params)
+		if err != nil {
+			logrus.Warnf("drop the api override of %s: %s", tradingPair, err.Error())
+			delete(b.overrides, tradingPair)
+			continue
+		}
+		logrus.Warnf("%s keeps the params set by the api, %s is ignored for it until the restart", tradingPair, next.Path)
+		next = overridden
 	}
+	return next
+}
 
-	var debt = auction.AvailableDebt
-	var collateral = auction.AvailableCollateral
-	if freeBalance.LessThan(debt) {
-		logrus.Warnf(`Balance not enough, you could repay %s %s debt`, freeBalance.String(), auction.DebtSymbol)
-		debt = freeBalance
-		collateral = debt.Div(auction.AvailableDebt).Mul(auction.AvailableCollateral)
+// apply the reloadable parameters of next, from config.json or the api
+func (b *BidderBot) applyConfig(next *config.Config) error {
+	var availableMarkets []string
+	for tradingPair := range b.DdexClient.Markets {
+		availableMarkets = append(availableMarkets, tradingPair)
+	}
+	if err := next.ValidateMarkets(availableMarkets); err != nil {
+		return err
 	}
 
-	// amount must greater than min usd size
-	collateralPrice, err := b.DdexClient.GetAssetUSDPrice(auction.CollateralSymbol)
+	updated, changes, ignored := b.Config.Update(next)
+	for _, change := range ignored {
+		logrus.Warnf("config change %s needs a restart to take effect", change)
+	}
+	for _, change := range changes {
+		logrus.Infof("config change %s", change)
+	}
+	b.mu.Lock()
+	b.Config = updated
+	b.mu.Unlock()
+	b.Alerter.SetInterval(updated.AlertInterval)
+	b.saveConfigSnapshot()
+	return nil
+}
+
+func (b *BidderBot) saveConfigSnapshot() {
+	id, err := b.Store.InsertConfigSnapshot(b.Config.Snapshot())
 	if err != nil {
+		logrus.Errorf("record config snapshot failed: %s", err.Error())
 		return
 	}
-	collateralValue := collateral.Mul(collateralPrice)
-	if collateral.Mul(collateralPrice).LessThanOrEqual(b.MinOrderValueUSD) {
-		err = errors.Errorf("collateral usd value %s$ too small", collateralValue.String())
+	b.configID = id
+}
+
+// usd price for the records, zero if unknown
+func (b *BidderBot) usdPrice(symbol string) decimal.Decimal {
+	price, err := b.DdexClient.GetAssetUSDPrice(symbol)
+	if err != nil {
+		logrus.Debugf("get %s usd price failed: %s", symbol, err.Error())
+		return decimal.Zero
+	}
+	return price
+}
+
+func (b *BidderBot) stopping() bool {
+	select {
+	case <-b.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *BidderBot) tryFillAuction(auction *client.Auction, blockNum int64) (err error) {
+	decision := newDecision(auction, blockNum)
+	decision.ConfigSnapshotID = b.configID
+	defer func() {
+		if err != nil {
+			decision.Error = err.Error()
+		}
+		b.recordDecision(decision)
+	}()
+
+	if b.Paused() {
+		skip(decision, "paused")
+		return nil
+	}
+	var funds = make([]Funds, len(b.Accounts))
+	for i, account := range b.Accounts {
+		funds[i] = account
+	}
+	market, parts, err := decideBid(b.Config, b.DdexClient, funds, auction, decision)
+	if err != nil || decision.Action != storage.DECISIONBID {
 		return
 	}
+	gasPriceInGwei := web3.GetGasPriceGwei() + int64(market.GasPriceTipsGwei())
+	logrus.Debugf("use gas price %d gwei", gasPriceInGwei)
+	decision.GasPriceGwei = gasPriceInGwei
+	debt, receive := decision.RepayDebt, decision.ExpectedReceiveDebt
+
+	// prices at decision time, recorded with the bids
+	var template = storage.Bid{
+		ConfigSnapshotID:    b.configID,
+		AuctionID:           auction.ID,
+		Market:              auction.TradingPair,
+		DebtSymbol:          auction.DebtSymbol,
+		CollateralSymbol:    auction.CollateralSymbol,
+		BlockNumber:         blockNum,
+		AvailableDebt:       auction.AvailableDebt,
+		AvailableCollateral: auction.AvailableCollateral,
+		AuctionPrice:        auction.Price,
+		DebtPriceUSD:        b.usdPrice(auction.DebtSymbol),
+		CollateralPriceUSD:  decision.CollateralPriceUSD,
+		EthPriceUSD:         b.usdPrice("ETH"),
+		GasPriceGwei:        gasPriceInGwei,
+	}
+
+	// send every part before waiting, so they can be mined in the same block
+	var bids = make([]*storage.Bid, len(b.Accounts))
+	var intents = make([]*storage.Intent, len(b.Accounts))
+	for i, account := range b.Accounts {
+		if parts[i].IsZero() {
+			continue
+		}
+		bid := template
+		bid.Account = account.DdexClient.Address
+		bid.RepayDebt = parts[i]
+		bid.ExpectedReceiveDebt = receive.Mul(parts[i]).Div(debt)
+
+		intent, sendErr := b.sendBid(account, auction, parts[i], gasPriceInGwei)
+		if sendErr != nil {
+			logrus.Errorf("account %s bid %s%s failed: %s", account.Name, parts[i].String(), auction.DebtSymbol, sendErr.Error())
+			bid.Status = storage.BIDFAILED
+			bid.Error = sendErr.Error()
+			err = sendErr
+			metrics.Bids.Inc(auction.TradingPair, storage.BIDFAILED)
+		} else {
+			logrus.Infof("account %s send tx %s", account.Name, intent.TxHash)
+			bid.Status = storage.BIDSENT
+			bid.TxHash = intent.TxHash
+			metrics.Bids.Inc(auction.TradingPair, storage.BIDSENT)
+			bids[i] = &bid
+			intents[i] = intent
+		}
+
+		// the bid is settled even if it is not recorded, the collateral must be hedged anyway
+		var insertErr error
+		if bid.ID, insertErr = b.Store.InsertBid(&bid); insertErr != nil {
+			logrus.Errorf("record bid of account %s failed: %s", account.Name, insertErr.Error())
+			err = errors.Wrap(insertErr, "record bid failed")
+		} else if intent != nil {
+			intent.BidID = bid.ID
+			b.advanceIntent(intent, storage.INTENTSENT)
+		}
+	}
+
+	for i, account := range b.Accounts {
+		if bids[i] == nil {
+			continue
+		}
+		if settleErr := b.settleBid(account, auction, market, &storage.Trade{Bid: bids[i]}, intents[i], false); settleErr != nil {
+			logrus.Errorf("account %s: %s", account.Name, settleErr.Error())
+			err = settleErr
+		}
+	}
+
+	return
+}
 
-	// check auction profitable
-	receive, err := b.DdexClient.QuerySellAssetReceiveAmount(auction.TradingPair, auction.CollateralSymbol, collateral)
+// sign the bid, journal it and broadcast it. A bid is never sent before it is journaled.
+func (b *BidderBot) sendBid(account *Account, auction *client.Auction, repayDebt decimal.Decimal, gasPriceInGwei int64) (intent *storage.Intent, err error) {
+	signed, err := account.BidderClient.SignFillAuction(auction, repayDebt, gasPriceInGwei)
 	if err != nil {
 		return
 	}
+	intent = &storage.Intent{
+		Account:      account.DdexClient.Address,
+		AuctionID:    auction.ID,
+		RepayDebt:    repayDebt,
+		GasPriceGwei: gasPriceInGwei,
+		Nonce:        signed.Nonce,
+		TxHash:       signed.TxHash,
+		RawTx:        signed.RawTx,
+		State:        storage.INTENTSIGNED,
+	}
+	if intent.ID, err = b.Store.InsertIntent(intent); err != nil {
+		return nil, errors.Wrap(err, "journal bid failed")
+	}
+	if _, err = account.BidderClient.SendRawTransaction(signed.RawTx); err != nil {
+		intent.Error = err.Error()
+		b.advanceIntent(intent, storage.INTENTFAILED)
+		return nil, err
+	}
+	return
+}
 
-	if receive.LessThanOrEqual(debt.Add(debt.Mul(b.ProfitMargin))) {
-		logrus.Warnf("auction price not profitable, wait next block")
+func (b *BidderBot) advanceIntent(intent *storage.Intent, state string) {
+	if intent == nil {
 		return
-	} else {
-		logrus.Infof("auction price profitable!")
-		gasPriceInGwei := web3.GetGasPriceGwei() + int64(b.GasPriceTipsGwei)
-		logrus.Debugf("use gas price %d gwei", gasPriceInGwei)
-		txHash, err := b.BidderClient.FillAuction(auction, debt, gasPriceInGwei)
-		if err != nil {
-			return err
-		}
-		logrus.Infof("send tx %s", txHash)
+	}
+	intent.State = state
+	if err := b.Store.UpdateIntent(intent); err != nil {
+		logrus.Errorf("journal bid %s %s failed: %s", intent.TxHash, state, err.Error())
+	}
+}
 
-		bidderRepay, collateralForBidder, gasUsed, err := b.BidderClient.GetFillAuctionRes(txHash, auction)
-		gasCost := gasUsed.Mul(decimal.New(gasPriceInGwei, -9))
+// wait for the bid to be mined and hedge the collateral received. Steps already recorded in the ledger are
+// skipped, so a bid resumed after a crash is settled by the same code. A resumed bid may have been hedged
+// before its hedge order was journaled, ddex is searched for the order before hedging again.
+func (b *BidderBot) settleBid(account *Account, auction *client.Auction, market *config.Market, trade *storage.Trade, intent *storage.Intent, resumed bool) (err error) {
+	bid := trade.Bid
+	if trade.Fill == nil {
+		bidderRepay, collateralForBidder, gasUsed, blockNumber, waitErr := account.BidderClient.GetFillAuctionRes(bid.TxHash, auction)
+		if waitErr != nil {
+			// the intent stays sent, the next start broadcasts the bid again or fails it
+			return errors.Wrapf(waitErr, "bid %s not settled", bid.TxHash)
+		}
+		gasCost := gasUsed.Mul(decimal.New(bid.GasPriceGwei, -9))
 		logrus.Infof(
 			"fill auction: repayDebt %s%s receiveCollateral %s%s gasCost %sETH",
 			bidderRepay.String(),
@@ -108,54 +350,146 @@ func (b *BidderBot) tryFillAuction(auction *client.Auction) (err error) {
 			auction.CollateralSymbol,
 			gasCost.String())
 
+		status := storage.BIDFILLED
 		if collateralForBidder.IsZero() {
-			utils.InsertFailedBid(txHash, int(auction.ID), auction.DebtSymbol, auction.CollateralSymbol, gasCost.String())
-			b.updatePnlView()
-			err = errors.New("bid transaction failed")
-			return err
+			status = storage.BIDREVERTED
+			b.Alerter.Fire(alert.BIDREVERTED, bid.TxHash, "account %s bid %s%s on auction #%d reverted, %sETH of gas lost",
+				account.Name, bid.RepayDebt.String(), auction.DebtSymbol, auction.ID, gasCost.String())
 		}
-		// todo: if hedge failed anyway, give a red alert
-		ddexOrderId, ddexSellCollateral, ddexReceiveDebt, err := b.DdexClient.PromisedMarketSellAsset(auction.TradingPair, auction.CollateralSymbol, collateralForBidder, b.MaxSlippage)
-		if err != nil {
-			return err
+		metrics.Bids.Inc(auction.TradingPair, status)
+		trade.Fill = &storage.Fill{
+			BidID:             bid.ID,
+			BlockNumber:       blockNumber,
+			RepayDebt:         bidderRepay,
+			ReceiveCollateral: collateralForBidder,
+			GasUsed:           gasUsed.IntPart(),
+			GasCost:           gasCost,
 		}
-		logrus.Infof("hedge at ddex market: sell %s%s receive %s%s",
-			ddexSellCollateral.String(),
-			auction.CollateralSymbol,
-			ddexReceiveDebt.String(),
-			auction.DebtSymbol,
-		)
+		if recordErr := b.Store.InsertFill(trade.Fill, status); recordErr != nil {
+			logrus.Errorf("record fill of %s failed: %s", bid.TxHash, recordErr.Error())
+			err = errors.Wrap(recordErr, "record fill failed")
+		} else {
+			b.advanceIntent(intent, storage.INTENTMINED)
+		}
+	}
+	collateralForBidder := trade.Fill.ReceiveCollateral
 
-		utils.InsertAuctionRes(
-			txHash,
-			int(auction.ID),
-			auction.DebtSymbol,
-			auction.CollateralSymbol,
-			bidderRepay.String(),
-			collateralForBidder.String(),
-			ddexOrderId,
-			ddexSellCollateral.String(),
-			ddexReceiveDebt.String(),
-			gasCost.String(),
-		)
+	if collateralForBidder.IsZero() {
+		b.advanceIntent(intent, storage.INTENTRECORDED)
 		b.updatePnlView()
+		err = errors.New("bid transaction failed")
+		return err
+	}
+	if len(trade.HedgeOrders) > 0 {
+		b.advanceIntent(intent, storage.INTENTRECORDED)
+		return
+	}
+
+	var order *client.OrderRes
+	var ddexSellCollateral, ddexReceiveDebt decimal.Decimal
+	if resumed {
+		if order, err = b.findHedgeOrder(account, trade, intent); err != nil {
+			return
+		}
+	}
+	if order != nil {
+		ddexSellCollateral, ddexReceiveDebt = order.SoldAndReceived()
+	} else {
+		var hedgeErr error
+		order, ddexSellCollateral, ddexReceiveDebt, hedgeErr = account.DdexClient.PromisedMarketSellAsset(auction.TradingPair, auction.CollateralSymbol, collateralForBidder, market.MaxSlippage)
+		if hedgeErr != nil {
+			b.Alerter.Fire(alert.HEDGEFAILED, bid.TxHash, "account %s failed to hedge %s%s received by bid %s: %s",
+				account.Name, collateralForBidder.String(), auction.CollateralSymbol, bid.TxHash, hedgeErr.Error())
+			return hedgeErr
+		}
+		if intent != nil {
+			intent.HedgeOrderId = order.Id
+			b.advanceIntent(intent, storage.INTENTHEDGED)
+		}
+	}
+	logrus.Infof("hedge at ddex market: sell %s%s receive %s%s",
+		ddexSellCollateral.String(),
+		auction.CollateralSymbol,
+		ddexReceiveDebt.String(),
+		auction.DebtSymbol,
+	)
+	if slippage, ok := hedgeSlippage(bid, ddexSellCollateral, ddexReceiveDebt); ok {
+		metrics.HedgeSlippage.Observe(slippage, auction.TradingPair)
+	}
+
+	hedgeOrder := newHedgeOrder(bid.ID, auction.TradingPair, order, ddexSellCollateral, ddexReceiveDebt)
+	if hedgeOrder.Status != storage.HEDGEFILLED {
+		b.Alerter.Fire(alert.HEDGEFAILED, bid.TxHash, "account %s hedge order %s of bid %s is %s: sold %s of %s%s",
+			account.Name, order.Id, bid.TxHash, hedgeOrder.Status, ddexSellCollateral.String(), order.Amount.String(), auction.CollateralSymbol)
+	}
+	if recordErr := b.Store.InsertHedgeOrder(hedgeOrder); recordErr != nil {
+		logrus.Errorf("record hedge order %s failed: %s", order.Id, recordErr.Error())
+		err = errors.Wrap(recordErr, "record hedge order failed")
+	} else {
+		b.advanceIntent(intent, storage.INTENTRECORDED)
 	}
+	b.updatePnlView()
 
 	return
 }
 
+// shortfall of the hedge price from the orderbook estimate the bid was decided on, negative if the hedge did better.
+// Unknown for bids recorded without the estimate.
+func hedgeSlippage(bid *storage.Bid, sellAmount, receiveAmount decimal.Decimal) (slippage float64, ok bool) {
+	if !bid.ExpectedReceiveDebt.IsPositive() || !bid.AvailableDebt.IsPositive() || !bid.AvailableCollateral.IsPositive() ||
+		!bid.RepayDebt.IsPositive() || !sellAmount.IsPositive() {
+		return
+	}
+	expectedCollateral := bid.RepayDebt.Mul(bid.AvailableCollateral).Div(bid.AvailableDebt)
+	expectedPrice := bid.ExpectedReceiveDebt.Div(expectedCollateral)
+	price := receiveAmount.Div(sellAmount)
+	slippage, _ = decimal.New(1, 0).Sub(price.Div(expectedPrice)).Float64()
+	return slippage, true
+}
+
+func newHedgeOrder(bidID int64, tradingPair string, order *client.OrderRes, sellAmount, receiveAmount decimal.Decimal) *storage.HedgeOrder {
+	status := storage.HEDGEFILLED
+	if sellAmount.IsZero() {
+		status = storage.HEDGEUNFILLED
+	} else if sellAmount.LessThan(order.Amount) {
+		status = storage.HEDGEPARTIAL
+	}
+	return &storage.HedgeOrder{
+		BidID:         bidID,
+		Market:        tradingPair,
+		Side:          order.Side,
+		DdexOrderId:   order.Id,
+		Amount:        order.Amount,
+		PriceLimit:    order.Price,
+		SellAmount:    sellAmount,
+		ReceiveAmount: receiveAmount,
+		AvgPrice:      order.AvgPrice,
+		FeeRate:       order.TakerFeeRate,
+		GasFee:        order.GasFeeAmount,
+		Status:        status,
+	}
+}
+
 func (b *BidderBot) updatePnlView() {
-	position, err := utils.QueryPosition()
+	trades, err := b.Store.QueryTrades(storage.Filter{})
 	if err != nil {
+		logrus.Errorf("query trades failed: %s", err.Error())
 		return
 	}
-	var price = map[string]decimal.Decimal{}
-	for symbol, _ := range position {
-		p, err := b.DdexClient.GetAssetUSDPrice(symbol)
-		if err != nil {
-			p = decimal.Zero
-		}
-		price[symbol] = p
+	prices, err := client.GetAssetUSDPrices(b.Config.DdexUrl)
+	if err != nil {
+		logrus.Errorf("get usd prices failed: %s", err.Error())
+	}
+	report := pnl.Compute(trades, prices, pnl.DAILY)
+	for kind, value := range map[string]decimal.Decimal{
+		"realized":   report.RealizedUSD,
+		"unrealized": report.UnrealizedUSD,
+		"gas":        report.GasUSD,
+		"fees":       report.FeesUSD,
+	} {
+		usd, _ := value.Float64()
+		metrics.PnlUSD.Set(usd, kind)
 	}
-	UpdatePnlView(position, price)
+	b.checkUnhedged(report)
+	UpdatePnlView(report)
 }
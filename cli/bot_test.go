@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/simulator"
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"auctionBidder/web3"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestBidAndHedge(t *testing.T) {
+	d := decimal.RequireFromString
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT",
+		[]*simulator.Level{{Price: d("199"), Amount: d("10")}, {Price: d("198"), Amount: d("10")}},
+		[]*simulator.Level{{Price: d("201"), Amount: d("10")}})
+	profitable := s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))   // 2.4ETH for 450USDT
+	unprofitable := s.AddAuction("USDT", "ETH", d("500"), d("2"), d("0.8")) // 1.6ETH for 500USDT
+
+	bot, store, blocks := newTestBot(t, s)
+	address := bot.Accounts[0].DdexClient.Address
+	go bot.Run()
+	// the next block is taken once the first one is handled
+	first := s.BlockNumber()
+	blocks <- first
+	blocks <- s.Mine()
+	bot.Stop()
+
+	if auction := s.Auction(profitable); !auction.Finished {
+		t.Errorf("auction %d not filled: %+v", profitable, auction)
+	}
+	if auction := s.Auction(unprofitable); auction.Finished || !auction.LeftDebt.Equal(d("500")) {
+		t.Errorf("auction %d should not be filled: %+v", unprofitable, auction)
+	}
+
+	trades, _ := store.QueryTrades(storage.Filter{})
+	if len(trades) != 1 || trades[0].Fill == nil || len(trades[0].HedgeOrders) != 1 {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	trade := trades[0]
+	if trade.Bid.Status != storage.BIDFILLED || trade.Fill.RepayDebt.String() != "450" || trade.Fill.ReceiveCollateral.String() != "2.4" {
+		t.Errorf("unexpected bid %+v fill %+v", trade.Bid, trade.Fill)
+	}
+	hedge := trade.HedgeOrders[0]
+	if hedge.Status != storage.HEDGEFILLED || hedge.SellAmount.String() != "2.4" || hedge.ReceiveAmount.String() != "477.6" || hedge.FeeRate.String() != "0.001" {
+		t.Errorf("unexpected hedge %+v", hedge)
+	}
+	if intents, _ := store.QueryOpenIntents(); len(intents) != 0 {
+		t.Errorf("bids left open: %+v", intents)
+	}
+
+	// 450USDT repaid, 477.6USDT received minus 0.1% fee, 150000 gas at 30gwei
+	if usdt, eth := s.Balance(address, "USDT"), s.Balance(address, "ETH"); usdt.String() != "1027.1224" || !eth.IsZero() {
+		t.Errorf("unexpected balances %sUSDT %sETH", usdt, eth)
+	}
+	if gas := s.EthBalance(address); gas.String() != "0.9955" {
+		t.Errorf("unexpected ether left %s", gas)
+	}
+
+	decisions, _ := store.QueryDecisions(storage.Filter{})
+	var bids, skips int
+	for _, decision := range decisions {
+		if decision.BlockNumber != first {
+			continue
+		}
+		if decision.Action == storage.DECISIONBID && decision.AuctionID == profitable {
+			bids++
+		} else if decision.Action == storage.DECISIONSKIP && decision.AuctionID == unprofitable && decision.Reason == "not_profitable" {
+			skips++
+		}
+	}
+	if bids != 1 || skips != 1 {
+		t.Errorf("unexpected decisions %d bids %d skips of %d", bids, skips, len(decisions))
+	}
+}
+
+// a bot of one account with 1000USDT and 1ETH for gas, bidding on ETH-USDT
+func newTestBot(t *testing.T, s *simulator.Simulator) (bot *BidderBot, store *storage.MemoryStore, blocks chan int64) {
+	d := decimal.RequireFromString
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := web3.NewLocalSigner(privateKey)
+	s.SetBalance(signer.Address(), "USDT", d("1000"))
+	s.SetEthBalance(signer.Address(), d("1"))
+	ddexClient, err := client.NewDdexClient(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bidderClient, err := client.NewBidderClient(signer, ddexClient.Assets, ddexClient.Markets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Markets:          []string{"ETH-USDT"},
+		ProfitMargin:     d("0.01"),
+		MaxSlippage:      d("0.05"),
+		MinOrderValueUSD: d("100"),
+		DdexUrl:          simulator.DdexUrl,
+	}
+	store = storage.NewMemoryStore()
+	blocks = make(chan int64)
+	bot = NewBidderBot([]*Account{{Name: "default", BidderClient: bidderClient, DdexClient: ddexClient}}, blocks, cfg, store, nil)
+	return
+}
+
+func TestUnminedBidLeftOpen(t *testing.T) {
+	d := decimal.RequireFromString
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT", []*simulator.Level{{Price: d("199"), Amount: d("10")}}, nil)
+	s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+	s.AutoMine = false
+
+	bot, store, blocks := newTestBot(t, s)
+	bot.BidderClient.ReceiptTimeout = 0
+	go bot.Run()
+	blocks <- s.BlockNumber()
+	bot.do(func() {}) // commands run once the block is handled
+	bot.Stop()
+
+	// the bid is still pending, nothing is recorded as settled
+	intents, _ := store.QueryOpenIntents()
+	if len(intents) != 1 || intents[0].State != storage.INTENTSENT {
+		t.Fatalf("the intent should be left sent: %+v", intents)
+	}
+	trades, _ := store.QueryTrades(storage.Filter{})
+	if len(trades) != 1 || trades[0].Fill != nil || trades[0].Bid.Status != storage.BIDSENT {
+		t.Errorf("unexpected trades %+v", trades)
+	}
+}
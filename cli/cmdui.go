@@ -2,6 +2,7 @@ package cli
 
 import (
 	"auctionBidder/client"
+	"auctionBidder/pnl"
 	"auctionBidder/utils"
 	"fmt"
 	"github.com/fatih/color"
@@ -77,6 +78,9 @@ func quit(g *gocui.Gui, v *gocui.View) error {
 }
 
 func UpdateAuctionView(auctions []*client.Auction) {
+	if DefaultGui == nil {
+		return
+	}
 	v, err := DefaultGui.View("auction")
 	if err != nil {
 		logrus.Error(err)
@@ -103,40 +107,72 @@ func UpdateAuctionView(auctions []*client.Auction) {
 	}
 }
 
-func UpdatePnlView(position map[string]decimal.Decimal, price map[string]decimal.Decimal) {
+func UpdatePnlView(report *pnl.Report) {
+	if DefaultGui == nil {
+		return
+	}
 	DefaultGui.Update(func(g *gocui.Gui) error {
 		v, _ := g.View("pnl")
 		v.Clear()
-		totalValue := decimal.Zero
-		for symbol, _ := range position {
-			value := position[symbol].Mul(price[symbol])
-			totalValue = totalValue.Add(value)
-			fmt.Fprintln(v, fmt.Sprintf("%s: %s (%s)",
-				symbol,
-				pnlStr(position[symbol], symbol),
-				pnlStr(value, "$")))
+		today := time.Now().UTC().Format("2006-01-02")
+		for _, period := range report.Periods {
+			if period.Label == today {
+				fmt.Fprintln(v, "today:"+pnlStr(period.RealizedUSD, "$"))
+			}
+		}
+		fmt.Fprintln(v, "realized:"+pnlStr(report.RealizedUSD, "$"))
+		fmt.Fprintln(v, fmt.Sprintf("  gas: %s fees: %s",
+			pnlStr(report.GasUSD.Neg(), "$"),
+			pnlStr(report.FeesUSD.Neg(), "$")))
+		for _, position := range report.Positions {
+			if position.Priced {
+				fmt.Fprintln(v, fmt.Sprintf("%s: %s (%s)",
+					position.Symbol,
+					position.Amount.String(),
+					pnlStr(position.UnrealizedUSD, "$")))
+			} else {
+				fmt.Fprintln(v, fmt.Sprintf("%s: %s (no price)", position.Symbol, position.Amount.String()))
+			}
+		}
+		fmt.Fprintln(v, "unrealized:"+pnlStr(report.UnrealizedUSD, "$"))
+		fmt.Fprintln(v, "total:"+pnlStr(report.RealizedUSD.Add(report.UnrealizedUSD), "$"))
+		if report.Unpriced > 0 {
+			fmt.Fprintln(v, fmt.Sprintf("%d old bids without prices", report.Unpriced))
 		}
-		fmt.Fprintln(v, "total:"+pnlStr(totalValue, "$"))
 		return nil
 	})
 }
 
-func UpdateInventoryView(ddexClient *client.DdexClient) {
-	inventory, err := ddexClient.GetInventory()
-	if err != nil {
+// UpdateInventoryView fetches the balances of the accounts, which also updates the balance metrics.
+func UpdateInventoryView(accounts []*Account) {
+	var inventories = make([]client.Inventory, len(accounts))
+	for i, account := range accounts {
+		inventory, err := account.DdexClient.GetInventory()
+		if err != nil {
+			return
+		}
+		inventories[i] = inventory
+	}
+	if DefaultGui == nil {
 		return
 	}
 	DefaultGui.Update(func(g *gocui.Gui) error {
 		v, _ := g.View("inventory")
 		v.Clear()
-		fmt.Fprintln(v, fmt.Sprintf("Your address:%s", ddexClient.Address))
-		symbolList := []string{}
-		for symbol, _ := range inventory {
-			symbolList = append(symbolList, symbol)
-		}
-		sort.Strings(symbolList)
-		for _, symbol := range symbolList {
-			fmt.Fprintln(v, fmt.Sprintf("[%s] %s", symbol, inventory[symbol].Free.StringFixed(3)))
+		for i, account := range accounts {
+			if len(accounts) == 1 {
+				fmt.Fprintln(v, fmt.Sprintf("Your address:%s", account.DdexClient.Address))
+			} else {
+				fmt.Fprintln(v, fmt.Sprintf("%s:%s", account.Name, account.DdexClient.Address))
+			}
+			symbolList := []string{}
+			for symbol, _ := range inventories[i] {
+				symbolList = append(symbolList, symbol)
+			}
+			sort.Strings(symbolList)
+			for _, symbol := range symbolList {
+				fmt.Fprintln(v, fmt.Sprintf("[%s] %s", symbol, inventories[i][symbol].Free.StringFixed(3)))
+			}
 		}
 		return nil
 	})
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// In headless mode DefaultGui stays nil, so every Update*View is a no-op
+// and logs are written to stdout as JSON for systemd or kubernetes to collect.
+
+func SetupHeadlessLogging(level logrus.Level) {
+	logrus.SetOutput(os.Stdout)
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetLevel(level)
+}
+
+// WaitForSignal blocks until the process receives SIGINT or SIGTERM.
+func WaitForSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(c)
+
+	s := <-c
+	logrus.Infof("receive signal %s, shutting down", s.String())
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// ResumeIntents settles the bids left open by a crash, in the order they were journaled. A transaction the
+// node doesn't know is broadcast again, then the bid is waited for, hedged and recorded like a new one.
+func (b *BidderBot) ResumeIntents() (err error) {
+	intents, err := b.Store.QueryOpenIntents()
+	if err != nil || len(intents) == 0 {
+		return
+	}
+	trades, err := b.Store.QueryTrades(storage.Filter{})
+	if err != nil {
+		return
+	}
+	byID := map[int64]*storage.Trade{}
+	byTxHash := map[string]*storage.Trade{}
+	for _, trade := range trades {
+		byID[trade.Bid.ID] = trade
+		if trade.Bid.TxHash != "" {
+			byTxHash[strings.ToLower(trade.Bid.TxHash)] = trade
+		}
+	}
+
+	for _, intent := range intents {
+		trade, ok := byID[intent.BidID]
+		if !ok {
+			trade = byTxHash[strings.ToLower(intent.TxHash)]
+		}
+		if resumeErr := b.resumeIntent(intent, trade); resumeErr != nil {
+			logrus.Errorf("resume bid %s of auction #%d failed: %s", intent.TxHash, intent.AuctionID, resumeErr.Error())
+		}
+	}
+	return
+}
+
+func (b *BidderBot) resumeIntent(intent *storage.Intent, trade *storage.Trade) (err error) {
+	account := b.account(intent.Account)
+	if account == nil {
+		return fmt.Errorf("account %s is not configured", intent.Account)
+	}
+	auction, err := account.BidderClient.GetSingleAuction(intent.AuctionID)
+	if err != nil {
+		return
+	}
+	market, ok := b.Config.Market(auction.TradingPair)
+	if !ok {
+		market = &config.Market{TradingPair: auction.TradingPair, MaxSlippage: b.Config.MaxSlippage}
+	}
+	logrus.Infof("resume %s bid %s of auction #%d", intent.State, intent.TxHash, intent.AuctionID)
+
+	// a transaction dropped by the node would never be mined, its nonce may be used by another one since
+	if intent.State == storage.INTENTSIGNED || intent.State == storage.INTENTSENT {
+		known, knownErr := account.BidderClient.IsTransactionKnown(intent.TxHash)
+		if knownErr != nil {
+			return knownErr
+		}
+		if !known {
+			if _, err = account.BidderClient.SendRawTransaction(intent.RawTx); err != nil {
+				intent.Error = err.Error()
+				b.advanceIntent(intent, storage.INTENTFAILED)
+				return
+			}
+		}
+	}
+
+	if trade == nil {
+		bid := &storage.Bid{
+			Account:          intent.Account,
+			AuctionID:        intent.AuctionID,
+			Market:           auction.TradingPair,
+			DebtSymbol:       auction.DebtSymbol,
+			CollateralSymbol: auction.CollateralSymbol,
+			RepayDebt:        intent.RepayDebt,
+			GasPriceGwei:     intent.GasPriceGwei,
+			TxHash:           intent.TxHash,
+			Status:           storage.BIDSENT,
+		}
+		if bid.ID, err = b.Store.InsertBid(bid); err != nil {
+			return
+		}
+		trade = &storage.Trade{Bid: bid}
+	}
+	intent.BidID = trade.Bid.ID
+	if intent.State == storage.INTENTSIGNED {
+		b.advanceIntent(intent, storage.INTENTSENT)
+	}
+
+	return b.settleBid(account, auction, market, trade, intent, true)
+}
+
+// the journaled hedge order, or an order placed before the crash but not journaled
+func (b *BidderBot) findHedgeOrder(account *Account, trade *storage.Trade, intent *storage.Intent) (order *client.OrderRes, err error) {
+	if intent.HedgeOrderId != "" {
+		return account.DdexClient.GetOrder(intent.HedgeOrderId)
+	}
+	orders, err := account.DdexClient.GetOrders(trade.Bid.Market, intent.CreatedAt)
+	if err != nil {
+		return
+	}
+	trades, err := b.Store.QueryTrades(storage.Filter{Markets: []string{trade.Bid.Market}})
+	if err != nil {
+		return
+	}
+	intents, err := b.Store.QueryOpenIntents()
+	if err != nil {
+		return
+	}
+	recorded := map[string]bool{}
+	for _, t := range trades {
+		for _, o := range t.HedgeOrders {
+			recorded[o.DdexOrderId] = true
+		}
+	}
+	// the journaled hedge orders of the other bids are not recorded yet
+	for _, i := range intents {
+		if i.HedgeOrderId != "" {
+			recorded[i.HedgeOrderId] = true
+		}
+	}
+	return matchHedgeOrder(trade, orderAmount(account, trade.Bid.Market, trade.Fill.ReceiveCollateral), orders, recorded, intent.CreatedAt), nil
+}
+
+func (b *BidderBot) account(address string) *Account {
+	for _, account := range b.Accounts {
+		if utils.IsAddressEqual(account.DdexClient.Address, address) {
+			return account
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/simulator"
+	"auctionBidder/storage"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestResumeIntents(t *testing.T) {
+	d := decimal.RequireFromString
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT",
+		[]*simulator.Level{{Price: d("199"), Amount: d("100")}},
+		[]*simulator.Level{{Price: d("201"), Amount: d("100")}})
+	for i := 0; i < 4; i++ {
+		s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+	}
+
+	bot, store, _ := newTestBot(t, s)
+	account := bot.Accounts[0]
+	address := account.DdexClient.Address
+	auctions, err := bot.BidderClient.GetAllAuctions()
+	if err != nil || len(auctions) != 4 {
+		t.Fatalf("unexpected auctions %v %v", auctions, err)
+	}
+
+	// the bids repay 100, 110, 120 and 130USDT of 450, the collateral received has more decimals than a ddex order
+	repay := func(auction *client.Auction) decimal.Decimal {
+		return decimal.New(90+10*auction.ID, 0)
+	}
+	journal := func(auction *client.Auction, state string, send bool) *storage.Intent {
+		signed, err := account.BidderClient.SignFillAuction(auction, repay(auction), 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		intent := &storage.Intent{Account: address, AuctionID: auction.ID, RepayDebt: repay(auction), GasPriceGwei: 30,
+			Nonce: signed.Nonce, TxHash: signed.TxHash, RawTx: signed.RawTx, State: storage.INTENTSIGNED}
+		if intent.ID, err = store.InsertIntent(intent); err != nil {
+			t.Fatal(err)
+		}
+		if send {
+			if _, err = account.BidderClient.SendRawTransaction(signed.RawTx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		intent.State = state
+		store.UpdateIntent(intent)
+		return intent
+	}
+	// the bid and its fill are recorded, the collateral is sold or not
+	record := func(auction *client.Auction, intent *storage.Intent, hedge bool) (order *client.OrderRes) {
+		bid := &storage.Bid{Account: address, AuctionID: auction.ID, Market: auction.TradingPair, DebtSymbol: "USDT",
+			CollateralSymbol: "ETH", RepayDebt: repay(auction), GasPriceGwei: 30, TxHash: intent.TxHash, Status: storage.BIDSENT}
+		intent.BidID, _ = store.InsertBid(bid)
+		repay, collateral, gasUsed, blockNumber, err := account.BidderClient.GetFillAuctionRes(intent.TxHash, auction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.InsertFill(&storage.Fill{BidID: intent.BidID, BlockNumber: blockNumber, RepayDebt: repay,
+			ReceiveCollateral: collateral, GasUsed: gasUsed.IntPart()}, storage.BIDFILLED)
+		if hedge {
+			if order, _, _, err = account.DdexClient.MarketSellAsset(auction.TradingPair, "ETH", collateral, d("0.05")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		store.UpdateIntent(intent)
+		return
+	}
+
+	// sent, no bid recorded
+	journal(auctions[0], storage.INTENTSENT, true)
+	// mined and hedged before the crash, the hedge order is not journaled
+	mined := journal(auctions[1], storage.INTENTMINED, true)
+	record(auctions[1], mined, true)
+	// hedged, the hedge order is not recorded
+	hedged := journal(auctions[2], storage.INTENTHEDGED, true)
+	hedged.HedgeOrderId = record(auctions[2], hedged, true).Id
+	store.UpdateIntent(hedged)
+	// signed but never broadcast, the last nonce
+	journal(auctions[3], storage.INTENTSIGNED, false)
+
+	if err = bot.ResumeIntents(); err != nil {
+		t.Fatal(err)
+	}
+
+	if intents, _ := store.QueryOpenIntents(); len(intents) != 0 {
+		t.Errorf("bids left open: %+v", intents)
+	}
+	trades, _ := store.QueryTrades(storage.Filter{})
+	if len(trades) != 4 {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	for _, trade := range trades {
+		if trade.Fill == nil || trade.Bid.Status != storage.BIDFILLED || len(trade.HedgeOrders) != 1 {
+			t.Errorf("auction #%d not settled: %+v %+v %+v", trade.Bid.AuctionID, trade.Bid, trade.Fill, trade.HedgeOrders)
+		}
+	}
+	// each collateral is sold once
+	if orders := s.Orders(address); len(orders) != 4 {
+		t.Errorf("%d hedge orders placed for 4 bids", len(orders))
+	}
+	for _, auction := range auctions {
+		if left := s.Auction(auction.ID).LeftDebt; !left.Equal(d("450").Sub(repay(auction))) {
+			t.Errorf("auction #%d filled once, %s debt left", auction.ID, left)
+		}
+	}
+}
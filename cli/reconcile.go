@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+type Reconciliation struct {
+	RecoveredBids   int          // mined bids sent before the bot died without a ledger row
+	RecoveredFills  int          // bids mined or reverted while the bot was down
+	RecoveredHedges int          // ddex hedge orders placed but not recorded
+	Pending         []string     // bids not mined yet
+	Unhedged        []*pnl.Trade // collateral received but never sold
+}
+
+// Reconcile brings the ledger up to date after a restart. The bot may have died after sending a bid and before
+// recording it, before recording its result, or before recording the hedge order. Fills from the last blocks
+// are read from the chain, hedge orders from ddex. Unhedged collateral is only reported, not sold.
+func (b *BidderBot) Reconcile(blocks int64) (report *Reconciliation, err error) {
+	report = &Reconciliation{}
+	for i, account := range b.Accounts {
+		if err = b.reconcileAccount(account, i == 0, blocks, report); err != nil {
+			return report, errors.Wrapf(err, "reconcile account %s", account.Name)
+		}
+	}
+
+	for _, trade := range report.Unhedged {
+		logrus.Warnf("auction #%d: %s%s received in %s was never hedged",
+			trade.AuctionID, trade.OpenAmount.String(), trade.OpenSymbol, trade.Market)
+	}
+	for _, txHash := range report.Pending {
+		logrus.Warnf("bid %s is not mined, pending or dropped", txHash)
+	}
+	logrus.Infof("reconciled %d bids, %d fills and %d hedge orders from the last %d blocks",
+		report.RecoveredBids, report.RecoveredFills, report.RecoveredHedges, blocks)
+	return
+}
+
+func (b *BidderBot) reconcileAccount(account *Account, first bool, blocks int64, report *Reconciliation) (err error) {
+	blockNumber, err := account.BidderClient.BlockNumber()
+	if err != nil {
+		return
+	}
+	fromBlock := blockNumber - blocks
+	if fromBlock < 0 {
+		fromBlock = 0
+	}
+	events, err := account.BidderClient.GetFillEvents(fromBlock)
+	if err != nil {
+		return
+	}
+
+	trades, err := b.accountTrades(account, first)
+	if err != nil {
+		return
+	}
+	byTxHash := map[string]*storage.Trade{}
+	for _, trade := range trades {
+		if trade.Bid.TxHash != "" {
+			byTxHash[strings.ToLower(trade.Bid.TxHash)] = trade
+		}
+	}
+
+	mined := map[string]bool{}
+	recovered := map[int64]bool{} // bids recorded now, created before the crash
+	for _, event := range events {
+		mined[strings.ToLower(event.TxHash)] = true
+		trade, ok := byTxHash[strings.ToLower(event.TxHash)]
+		if ok && trade.Fill != nil {
+			continue
+		}
+		if !ok {
+			bid := &storage.Bid{
+				Account:          account.DdexClient.Address,
+				AuctionID:        event.Auction.ID,
+				Market:           event.Auction.TradingPair,
+				DebtSymbol:       event.Auction.DebtSymbol,
+				CollateralSymbol: event.Auction.CollateralSymbol,
+				RepayDebt:        event.BidderRepay,
+				TxHash:           event.TxHash,
+				Status:           storage.BIDSENT,
+			}
+			if bid.ID, err = b.Store.InsertBid(bid); err != nil {
+				return
+			}
+			report.RecoveredBids++
+			recovered[bid.ID] = true
+			trade = &storage.Trade{Bid: bid}
+		}
+		if err = b.Store.InsertFill(&storage.Fill{
+			BidID:             trade.Bid.ID,
+			BlockNumber:       event.BlockNumber,
+			RepayDebt:         event.BidderRepay,
+			ReceiveCollateral: event.CollateralForBidder,
+			GasUsed:           event.GasUsed.IntPart(),
+			GasCost:           event.GasCost,
+		}, storage.BIDFILLED); err != nil {
+			return
+		}
+		report.RecoveredFills++
+	}
+
+	// sent bids without a fill event are reverted, older than the scanned blocks or still pending
+	for _, trade := range trades {
+		bid := trade.Bid
+		if trade.Fill != nil || bid.Status != storage.BIDSENT || bid.TxHash == "" || mined[strings.ToLower(bid.TxHash)] {
+			continue
+		}
+		auction, auctionErr := account.BidderClient.GetSingleAuction(bid.AuctionID)
+		if auctionErr != nil {
+			logrus.Errorf("get auction #%d of bid %s failed: %s", bid.AuctionID, bid.TxHash, auctionErr.Error())
+			continue
+		}
+		isMined, bidderRepay, collateralForBidder, gasUsed, fillBlock, receiptErr := account.BidderClient.GetMinedFillAuctionRes(bid.TxHash, auction)
+		if receiptErr != nil {
+			return receiptErr
+		}
+		if !isMined {
+			report.Pending = append(report.Pending, bid.TxHash)
+			continue
+		}
+		status := storage.BIDFILLED
+		if collateralForBidder.IsZero() {
+			status = storage.BIDREVERTED
+		}
+		if err = b.Store.InsertFill(&storage.Fill{
+			BidID:             bid.ID,
+			BlockNumber:       fillBlock,
+			RepayDebt:         bidderRepay,
+			ReceiveCollateral: collateralForBidder,
+			GasUsed:           gasUsed.IntPart(),
+			GasCost:           gasUsed.Mul(decimal.New(bid.GasPriceGwei, -9)),
+		}, status); err != nil {
+			return
+		}
+		report.RecoveredFills++
+	}
+
+	// the fills recorded above are in the ledger now
+	if trades, err = b.accountTrades(account, first); err != nil {
+		return
+	}
+	if err = b.reconcileHedges(account, trades, recovered, report); err != nil {
+		return
+	}
+
+	if trades, err = b.accountTrades(account, first); err != nil {
+		return
+	}
+	for _, trade := range trades {
+		if trade.Fill == nil || trade.Fill.BlockNumber < fromBlock {
+			continue
+		}
+		// the dust left by truncating the hedge order can't be sold
+		if computed := pnl.ComputeTrade(trade); computed != nil && orderAmount(account, trade.Bid.Market, computed.OpenAmount).IsPositive() {
+			report.Unhedged = append(report.Unhedged, computed)
+		}
+	}
+	return
+}
+
+// look for ddex orders hedging the filled bids without a recorded hedge order
+func (b *BidderBot) reconcileHedges(account *Account, trades []*storage.Trade, recovered map[int64]bool, report *Reconciliation) (err error) {
+	recorded := map[string]bool{}
+	for _, trade := range trades {
+		for _, order := range trade.HedgeOrders {
+			recorded[order.DdexOrderId] = true
+		}
+	}
+
+	var unhedged []*storage.Trade
+	since := map[string]int64{} // market -> creation of the oldest unhedged bid
+	for _, trade := range trades {
+		if trade.Fill == nil || !trade.Fill.ReceiveCollateral.IsPositive() || len(trade.HedgeOrders) > 0 {
+			continue
+		}
+		if oldest, ok := since[trade.Bid.Market]; !ok || bidCreatedAt(trade, recovered) < oldest {
+			since[trade.Bid.Market] = bidCreatedAt(trade, recovered)
+		}
+		unhedged = append(unhedged, trade)
+	}
+
+	orders := map[string][]*client.OrderRes{}
+	for market, createdAt := range since {
+		if orders[market], err = account.DdexClient.GetOrders(market, createdAt); err != nil {
+			return
+		}
+	}
+	for _, trade := range unhedged {
+		market := trade.Bid.Market
+		order := matchHedgeOrder(trade, orderAmount(account, trade.Bid.Market, trade.Fill.ReceiveCollateral), orders[market], recorded, bidCreatedAt(trade, recovered))
+		if order == nil {
+			continue
+		}
+		recorded[order.Id] = true
+		sellAmount, receiveAmount := order.SoldAndReceived()
+		if err = b.Store.InsertHedgeOrder(newHedgeOrder(trade.Bid.ID, market, order, sellAmount, receiveAmount)); err != nil {
+			return
+		}
+		report.RecoveredHedges++
+	}
+	return
+}
+
+// a recovered bid is recorded after its hedge order, any order may hedge it
+func bidCreatedAt(trade *storage.Trade, recovered map[int64]bool) int64 {
+	if recovered[trade.Bid.ID] {
+		return 0
+	}
+	return trade.Bid.CreatedAt
+}
+
+// ddex truncates the amount of an order to the amount decimals of the market
+func orderAmount(account *Account, tradingPair string, amount decimal.Decimal) decimal.Decimal {
+	if market, ok := account.DdexClient.Markets[tradingPair]; ok {
+		return utils.SetDecimal(amount, market.AmountDecimal)
+	}
+	return amount
+}
+
+// the hedge order is the first order since the bid selling exactly the amount of the hedge
+func matchHedgeOrder(trade *storage.Trade, amount decimal.Decimal, orders []*client.OrderRes, recorded map[string]bool, since int64) *client.OrderRes {
+	var candidates []*client.OrderRes
+	for _, order := range orders {
+		if recorded[order.Id] || !order.Amount.Equal(amount) || order.CreatedAt < since {
+			continue
+		}
+		// selling the base asset is a sell order, selling the quote asset a buy order
+		isBase := strings.HasPrefix(trade.Bid.Market, trade.Bid.CollateralSymbol+"-")
+		if isBase != (order.Side == utils.SELL) {
+			continue
+		}
+		candidates = append(candidates, order)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt < candidates[j].CreatedAt
+	})
+	return candidates[0]
+}
+
+// bids are recorded with the account address, bids recorded before accounts were introduced belong to the first account
+func (b *BidderBot) accountTrades(account *Account, first bool) (trades []*storage.Trade, err error) {
+	all, err := b.Store.QueryTrades(storage.Filter{})
+	if err != nil {
+		return
+	}
+	for _, trade := range all {
+		if utils.IsAddressEqual(trade.Bid.Account, account.DdexClient.Address) || (first && trade.Bid.Account == "") {
+			trades = append(trades, trade)
+		}
+	}
+	return
+}
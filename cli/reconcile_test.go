@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/simulator"
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+func TestMatchHedgeOrder(t *testing.T) {
+	trade := &storage.Trade{
+		Bid:  &storage.Bid{CreatedAt: 1000, Market: "ETH-USDT", CollateralSymbol: "ETH"},
+		Fill: &storage.Fill{ReceiveCollateral: decimal.RequireFromString("1.500000004")},
+	}
+	// the collateral truncated to the amount decimals of the market
+	amount := utils.SetDecimal(trade.Fill.ReceiveCollateral, 8)
+	orders := []*client.OrderRes{
+		{Id: "later", Side: utils.SELL, Amount: decimal.New(15, -1), CreatedAt: 3000},
+		{Id: "recorded", Side: utils.SELL, Amount: decimal.New(15, -1), CreatedAt: 1500},
+		{Id: "before", Side: utils.SELL, Amount: decimal.New(15, -1), CreatedAt: 900},
+		{Id: "buy", Side: utils.BUY, Amount: decimal.New(15, -1), CreatedAt: 1600},
+		{Id: "amount", Side: utils.SELL, Amount: decimal.New(1, 0), CreatedAt: 1700},
+		{Id: "first", Side: utils.SELL, Amount: decimal.New(150, -2), CreatedAt: 2000},
+	}
+	recorded := map[string]bool{"recorded": true}
+
+	if order := matchHedgeOrder(trade, amount, orders, recorded, trade.Bid.CreatedAt); order == nil || order.Id != "first" {
+		t.Errorf("unexpected hedge order %+v", order)
+	}
+
+	// collateral in the quote asset is sold with a buy order
+	trade.Bid.Market = "HOT-ETH"
+	if order := matchHedgeOrder(trade, amount, orders, recorded, trade.Bid.CreatedAt); order == nil || order.Id != "buy" {
+		t.Errorf("unexpected hedge order %+v", order)
+	}
+
+	recorded["buy"] = true
+	if order := matchHedgeOrder(trade, amount, orders, recorded, trade.Bid.CreatedAt); order != nil {
+		t.Errorf("no order should match, got %+v", order)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	d := decimal.RequireFromString
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT",
+		[]*simulator.Level{{Price: d("199"), Amount: d("100")}},
+		[]*simulator.Level{{Price: d("201"), Amount: d("100")}})
+	for i := 0; i < 4; i++ {
+		s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+	}
+
+	bot, store, _ := newTestBot(t, s)
+	account := bot.Accounts[0]
+	address := account.DdexClient.Address
+	auctions, err := bot.BidderClient.GetAllAuctions()
+	if err != nil || len(auctions) != 4 {
+		t.Fatalf("unexpected auctions %v %v", auctions, err)
+	}
+
+	// the bids repay 100, 110, 120 and 130USDT, the collateral received has more decimals than a ddex order
+	repay := func(auction *client.Auction) decimal.Decimal {
+		return decimal.New(90+10*auction.ID, 0)
+	}
+	bids := make([]*storage.Bid, len(auctions))
+	for i, auction := range auctions {
+		bids[i] = &storage.Bid{Account: address, AuctionID: auction.ID, Market: auction.TradingPair, DebtSymbol: "USDT",
+			CollateralSymbol: "ETH", RepayDebt: repay(auction), GasPriceGwei: 30, Status: storage.BIDSENT}
+		signed, err := account.BidderClient.SignFillAuction(auction, repay(auction), 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bids[i].TxHash = signed.TxHash
+		// the bid of the first auction is not recorded
+		if i > 0 {
+			if bids[i].ID, err = store.InsertBid(bids[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err = account.BidderClient.SendRawTransaction(signed.RawTx); err != nil {
+			t.Fatal(err)
+		}
+		_, collateral, gasUsed, blockNumber, err := account.BidderClient.GetFillAuctionRes(signed.TxHash, auction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the fill of the second auction is not recorded
+		if i > 1 {
+			store.InsertFill(&storage.Fill{BidID: bids[i].ID, BlockNumber: blockNumber, RepayDebt: repay(auction),
+				ReceiveCollateral: collateral, GasUsed: gasUsed.IntPart()}, storage.BIDFILLED)
+		}
+		// the collateral of the last auction is never sold, the hedges of the others are not recorded
+		if i < 3 {
+			if _, _, _, err = account.DdexClient.MarketSellAsset(auction.TradingPair, "ETH", collateral, d("0.05")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	report, err := bot.Reconcile(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.RecoveredBids != 1 || report.RecoveredFills != 2 || report.RecoveredHedges != 3 || len(report.Pending) != 0 {
+		t.Errorf("unexpected reconciliation %+v", report)
+	}
+	if len(report.Unhedged) != 1 || report.Unhedged[0].AuctionID != auctions[3].ID {
+		t.Errorf("unexpected unhedged trades %+v", report.Unhedged)
+	}
+
+	trades, _ := store.QueryTrades(storage.Filter{})
+	if len(trades) != 4 {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	for _, trade := range trades {
+		hedges := 1
+		if trade.Bid.AuctionID == auctions[3].ID {
+			hedges = 0
+		}
+		if trade.Fill == nil || trade.Bid.Status != storage.BIDFILLED || len(trade.HedgeOrders) != hedges {
+			t.Errorf("auction #%d not reconciled: %+v %+v %+v", trade.Bid.AuctionID, trade.Bid, trade.Fill, trade.HedgeOrders)
+		}
+	}
+}
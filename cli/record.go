@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/history"
+	"auctionBidder/web3"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"sync/atomic"
+	"time"
+)
+
+// record the orderbooks of the monitored markets and the auctions of the block in the background, so bidding
+// is not delayed. The block is not recorded if the previous one is still being fetched.
+func (b *BidderBot) record(blockNum int64, auctions []*client.Auction, prices map[string]decimal.Decimal) {
+	if b.Recorder == nil {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&b.recording, 0, 1) {
+		logrus.Warnf("block %d not recorded, still recording the previous one", blockNum)
+		return
+	}
+	markets := b.Config.Markets
+	go func() {
+		defer atomic.StoreInt32(&b.recording, 0)
+		block := &history.Block{
+			Number:       blockNum,
+			Time:         time.Now().Unix(),
+			GasPriceGwei: web3.GetGasPriceGwei(),
+			Auctions:     auctions,
+			Prices:       prices,
+			Orderbooks:   map[string]*client.Orderbook{},
+		}
+		for _, tradingPair := range markets {
+			orderbook, err := b.DdexClient.GetOrderbook(tradingPair)
+			if err != nil {
+				logrus.Warnf("record orderbook of %s failed: %s", tradingPair, err.Error())
+				continue
+			}
+			block.Orderbooks[tradingPair] = orderbook
+		}
+		if err := b.Recorder.Record(block); err != nil {
+			logrus.Errorf("record block %d failed: %s", blockNum, err.Error())
+		}
+	}()
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/storage"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// Quoter prices the collateral of an auction, from ddex for the bot or from recorded orderbooks for the backtester.
+type Quoter interface {
+	GetAssetUSDPrice(assetSymbol string) (price decimal.Decimal, err error)
+	QuerySellAssetReceiveAmount(tradingPair string, assetSymbol string, payAmount decimal.Decimal) (receiveAmount decimal.Decimal, err error)
+}
+
+// Funds is an account the strategy can bid with.
+type Funds interface {
+	FreeBalance(symbol string) (free decimal.Decimal, err error)
+	BidLimitUSD() decimal.Decimal // collateral usd value of a single bid, zero means no limit
+}
+
+func (account *Account) FreeBalance(symbol string) (free decimal.Decimal, err error) {
+	inventory, err := account.DdexClient.GetInventory()
+	if err != nil {
+		return free, errors.Wrapf(err, "get inventory of account %s failed", account.Name)
+	}
+	if balance, ok := inventory[symbol]; ok {
+		free = balance.Free
+	}
+	return
+}
+
+func (account *Account) BidLimitUSD() decimal.Decimal {
+	return account.MaxBidValueUSD
+}
+
+func newDecision(auction *client.Auction, blockNum int64) *storage.Decision {
+	return &storage.Decision{
+		BlockNumber:         blockNum,
+		AuctionID:           auction.ID,
+		Market:              auction.TradingPair,
+		DebtSymbol:          auction.DebtSymbol,
+		CollateralSymbol:    auction.CollateralSymbol,
+		AuctionPrice:        auction.Price,
+		Ratio:               auction.Ratio,
+		AvailableDebt:       auction.AvailableDebt,
+		AvailableCollateral: auction.AvailableCollateral,
+	}
+}
+
+func skip(decision *storage.Decision, reason string) {
+	decision.Action, decision.Reason = storage.DECISIONSKIP, reason
+}
+
+// decideBid decides if the auction is worth bidding on and how much debt each of funds repays, filling in
+// the inputs and the result of the decision. It doesn't send anything, the bot and the backtester share it.
+// Errors are returned with a skip decision. The gas price is left to the caller.
+func decideBid(cfg *config.Config, quoter Quoter, funds []Funds, auction *client.Auction, decision *storage.Decision) (market *config.Market, parts []decimal.Decimal, err error) {
+	// check if the market is under monitor
+	market, ok := cfg.Market(auction.TradingPair)
+	if !ok {
+		logrus.Debugf("auction trading pair %s is not in monitor list %s", auction.TradingPair, strings.Join(cfg.Markets, ","))
+		skip(decision, "not_monitored")
+		return
+	}
+	decision.ProfitMargin = market.ProfitMargin
+	if !market.Enabled {
+		logrus.Debugf("auction trading pair %s is disabled", auction.TradingPair)
+		skip(decision, "disabled")
+		return
+	}
+	logrus.Debugf("try fill auction %d", auction.ID)
+
+	// a repaid auction has no debt left to divide the collateral by
+	if auction.Finished || !auction.AvailableDebt.IsPositive() {
+		logrus.Debugf("auction %d is finished", auction.ID)
+		skip(decision, "finished")
+		return
+	}
+	if !auction.AvailableCollateral.IsPositive() {
+		logrus.Debugf("auction %d has no collateral left", auction.ID)
+		skip(decision, "no_collateral")
+		return
+	}
+	collateralPrice, err := quoter.GetAssetUSDPrice(auction.CollateralSymbol)
+	if err != nil {
+		skip(decision, "error")
+		return
+	}
+	decision.CollateralPriceUSD = collateralPrice
+	if !collateralPrice.IsPositive() {
+		err = errors.Errorf("no usd price of %s", auction.CollateralSymbol)
+		skip(decision, "no_price")
+		return
+	}
+	// debt to repay for 1 usd of collateral
+	debtPerUSD := auction.AvailableDebt.Div(auction.AvailableCollateral).Div(collateralPrice)
+
+	var debt = auction.AvailableDebt
+
+	// truncate order size by max bid value of the market
+	if market.MaxBidValueUSD.IsPositive() && debt.GreaterThan(market.MaxBidValueUSD.Mul(debtPerUSD)) {
+		logrus.Infof("collateral usd value %s$ exceeds max bid value %s$ of %s", auction.AvailableCollateral.Mul(collateralPrice).String(), market.MaxBidValueUSD.String(), market.TradingPair)
+		debt = market.MaxBidValueUSD.Mul(debtPerUSD)
+	}
+
+	// truncate order size by free balance and max bid value of each account
+	var capacity = make([]decimal.Decimal, len(funds))
+	var totalCapacity = decimal.Zero
+	for i, f := range funds {
+		free, freeErr := f.FreeBalance(auction.DebtSymbol)
+		if freeErr != nil {
+			logrus.Error(freeErr.Error())
+			continue
+		}
+		capacity[i] = free
+		decision.FreeDebt = decision.FreeDebt.Add(free)
+		if limit := f.BidLimitUSD(); limit.IsPositive() {
+			capacity[i] = decimal.Min(capacity[i], limit.Mul(debtPerUSD))
+		}
+		totalCapacity = totalCapacity.Add(capacity[i])
+	}
+	if totalCapacity.IsZero() {
+		err = errors.Errorf(`%s balance is zero`, auction.DebtSymbol)
+		skip(decision, "no_balance")
+		return
+	}
+	if totalCapacity.LessThan(debt) {
+		logrus.Warnf(`Balance not enough, you could repay %s %s debt`, totalCapacity.String(), auction.DebtSymbol)
+	}
+
+	// amount must greater than min usd size, so must be every part of a split bid
+	parts = allocate(capacity, debt, market.MinOrderValueUSD.Mul(debtPerUSD))
+	debt = decimal.Zero
+	for _, part := range parts {
+		debt = debt.Add(part)
+	}
+	collateral := debt.Div(auction.AvailableDebt).Mul(auction.AvailableCollateral)
+	decision.RepayDebt, decision.Collateral = debt, collateral
+	collateralValue := collateral.Mul(collateralPrice)
+	if collateralValue.LessThanOrEqual(market.MinOrderValueUSD) {
+		err = errors.Errorf("collateral usd value %s$ too small", collateralValue.String())
+		skip(decision, "too_small")
+		return
+	}
+
+	// check auction profitable
+	receive, err := quoter.QuerySellAssetReceiveAmount(auction.TradingPair, auction.CollateralSymbol, collateral)
+	if err != nil {
+		skip(decision, "error")
+		return
+	}
+	minReceive := debt.Add(debt.Mul(market.ProfitMargin))
+	decision.ExpectedReceiveDebt, decision.MinReceiveDebt = receive, minReceive
+
+	if receive.LessThanOrEqual(minReceive) {
+		logrus.Warnf("auction %d not profitable: selling %s%s receives %s%s, %s%s needed, wait next block",
+			auction.ID, collateral.String(), auction.CollateralSymbol, receive.String(), auction.DebtSymbol, minReceive.String(), auction.DebtSymbol)
+		skip(decision, "not_profitable")
+		return
+	}
+	logrus.Infof("auction price profitable!")
+	decision.Action, decision.Reason = storage.DECISIONBID, "profitable"
+	return
+}
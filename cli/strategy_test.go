@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/storage"
+	"github.com/shopspring/decimal"
+	"testing"
+)
+
+type stubQuoter struct{}
+
+func (stubQuoter) GetAssetUSDPrice(assetSymbol string) (decimal.Decimal, error) {
+	return decimal.New(200, 0), nil
+}
+
+func (stubQuoter) QuerySellAssetReceiveAmount(tradingPair string, assetSymbol string, payAmount decimal.Decimal) (decimal.Decimal, error) {
+	return payAmount.Mul(decimal.New(199, 0)), nil
+}
+
+type stubFunds struct{}
+
+func (stubFunds) FreeBalance(symbol string) (decimal.Decimal, error) {
+	return decimal.New(1000, 0), nil
+}
+
+func (stubFunds) BidLimitUSD() decimal.Decimal {
+	return decimal.Zero
+}
+
+func TestDecideBid(t *testing.T) {
+	d := decimal.RequireFromString
+	cfg := &config.Config{
+		Markets:          []string{"ETH-USDT"},
+		ProfitMargin:     d("0.01"),
+		MinOrderValueUSD: d("100"),
+	}
+	for _, c := range []struct {
+		name      string
+		auction   *client.Auction
+		action    string
+		reason    string
+		repayDebt string
+	}{
+		{"profitable", &client.Auction{AvailableDebt: d("450"), AvailableCollateral: d("2.4")}, storage.DECISIONBID, "profitable", "450"},
+		{"not profitable", &client.Auction{AvailableDebt: d("500"), AvailableCollateral: d("2.4")}, storage.DECISIONSKIP, "not_profitable", "500"},
+		{"repaid", &client.Auction{AvailableDebt: decimal.Zero, AvailableCollateral: d("2.4")}, storage.DECISIONSKIP, "finished", "0"},
+		{"finished", &client.Auction{AvailableDebt: d("450"), AvailableCollateral: d("2.4"), Finished: true}, storage.DECISIONSKIP, "finished", "0"},
+	} {
+		c.auction.TradingPair, c.auction.DebtSymbol, c.auction.CollateralSymbol = "ETH-USDT", "USDT", "ETH"
+		decision := newDecision(c.auction, 1)
+		decideBid(cfg, stubQuoter{}, []Funds{stubFunds{}}, c.auction, decision)
+		if decision.Action != c.action || decision.Reason != c.reason || decision.RepayDebt.String() != c.repayDebt {
+			t.Errorf("%s: unexpected decision %s %s repaying %s", c.name, decision.Action, decision.Reason, decision.RepayDebt)
+		}
+	}
+}
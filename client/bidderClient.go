@@ -1,108 +1,211 @@
 package client
 
 import (
+	"auctionBidder/hydro"
 	"auctionBidder/utils"
 	"auctionBidder/web3"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
 	"math/big"
 	"os"
-	"strings"
+	"sort"
 	"time"
 )
 
 type Auction struct {
-	ID                  int64
-	DebtSymbol          string
-	CollateralSymbol    string
-	TradingPair         string
-	AvailableDebt       decimal.Decimal
-	AvailableCollateral decimal.Decimal
-	Ratio               decimal.Decimal
-	Price               decimal.Decimal
-	Finished            bool
+	ID                  int64           `json:"id"`
+	DebtSymbol          string          `json:"debtSymbol"`
+	CollateralSymbol    string          `json:"collateralSymbol"`
+	TradingPair         string          `json:"tradingPair"`
+	AvailableDebt       decimal.Decimal `json:"availableDebt"`
+	AvailableCollateral decimal.Decimal `json:"availableCollateral"`
+	Ratio               decimal.Decimal `json:"ratio"`
+	Price               decimal.Decimal `json:"price"`
+	Finished            bool            `json:"finished"`
 }
 
 type BidderClient struct {
-	web3             *web3.Web3
-	hydroContract    *web3.Contract
-	bidderPrivateKey string
-	bidderAddress    string
-	assets           map[string]*Asset  // symbol -> asset
-	markets          map[string]*Market // trading pair -> market
+	web3          *web3.Web3
+	hydroContract *hydro.Hydro
+	bidderAddress string
+	assets        map[string]*Asset  // symbol -> asset
+	markets       map[string]*Market // trading pair -> market
+
+	ReceiptTimeout time.Duration // WaitForReceipt gives up after it, a dropped or underpriced transaction is never mined
 }
 
-func NewBidderClient(bidderPrivateKey string, assets map[string]*Asset, markets map[string]*Market) (client *BidderClient, err error) {
+func NewBidderClient(signer web3.Signer, assets map[string]*Asset, markets map[string]*Market) (client *BidderClient, err error) {
 	ethereumNodeUrl := os.Getenv("ETHEREUM_NODE_URL")
 	hydroContractAddress := os.Getenv("HYDRO_CONTRACT_ADDRESS")
 
 	web3 := web3.NewWeb3(ethereumNodeUrl)
-	bidderAddress, err := web3.AddPrivateKey(bidderPrivateKey)
-	if err != nil {
-		return
+	var bidderAddress string
+	if signer != nil { // read only without a signer
+		bidderAddress = web3.AddSigner(signer)
 	}
-	contract, err := web3.NewContract(utils.HydroAbi, hydroContractAddress)
+	contract, err := hydro.NewHydro(web3, hydroContractAddress)
 	if err != nil {
 		return
 	}
 	client = &BidderClient{
 		web3,
 		contract,
-		bidderPrivateKey,
 		bidderAddress,
 		assets,
 		markets,
+		10 * time.Minute,
 	}
 
 	return
 }
 
-func (client *BidderClient) FillAuction(
-	auction *Auction,
-	repayDebt decimal.Decimal,
-	gasPriceInGwei int64,
-) (txHash string, err error) {
-	nonce, err := client.web3.Rpc.EthGetTransactionCount(client.bidderAddress, "latest")
+func (client *BidderClient) send(gasPriceInGwei int64, sign signFunc) (txHash string, err error) {
+	signed, err := client.sign(gasPriceInGwei, sign)
+	if err != nil {
+		return
+	}
+	return client.SendRawTransaction(signed.RawTx)
+}
+
+// signFunc signs a transaction of the hydro bindings
+type signFunc func(tx *web3.SendTxParams) (txHash string, rawTx string, err error)
+
+// SignedTx is a transaction ready to broadcast.
+type SignedTx struct {
+	TxHash string
+	RawTx  string
+	Nonce  int64
+}
+
+func (client *BidderClient) sign(gasPriceInGwei int64, sign signFunc) (signed *SignedTx, err error) {
+	// every account has its own nonce stream, pending counts its transactions not mined yet
+	nonce, err := client.web3.Rpc.EthGetTransactionCount(client.bidderAddress, "pending")
 	if err != nil {
 		return
 	}
 	sendTxParams := &web3.SendTxParams{
-		client.bidderAddress,
-		big.NewInt(500000),
-		big.NewInt(gasPriceInGwei * 1000000000),
-		uint64(nonce),
+		FromAddress: client.bidderAddress,
+		GasLimit:    big.NewInt(500000),
+		GasPrice:    big.NewInt(gasPriceInGwei * 1000000000),
+		Nonce:       uint64(nonce),
+	}
+
+	txHash, rawTx, err := sign(sendTxParams)
+	if err != nil {
+		return
+	}
+	return &SignedTx{txHash, rawTx, int64(nonce)}, nil
+}
+
+func (client *BidderClient) SendRawTransaction(rawTx string) (txHash string, err error) {
+	return client.web3.Rpc.EthSendRawTransaction(rawTx)
+}
+
+// IsTransactionKnown checks if the node has seen the transaction, pending or mined.
+func (client *BidderClient) IsTransactionKnown(txHash string) (known bool, err error) {
+	tx, err := client.web3.Rpc.EthGetTransactionByHash(txHash)
+	if err != nil {
+		return
 	}
+	return tx.Hash != "", nil
+}
 
+// WaitForReceipt polls until the transaction is mined, or fails after ReceiptTimeout.
+func (client *BidderClient) WaitForReceipt(txHash string) (receipt *web3.TransactionReceipt, err error) {
+	deadline := time.Now().Add(client.ReceiptTimeout)
+	for true {
+		receipt, err = client.web3.Rpc.EthGetTransactionReceipt(txHash)
+		if err == nil && receipt != nil && receipt.BlockNumber != 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("transaction %s not mined after %s", txHash, client.ReceiptTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+	return
+}
+
+// SignFillAuction signs the bid without sending it, so it can be journaled before it is broadcast.
+func (client *BidderClient) SignFillAuction(
+	auction *Auction,
+	repayDebt decimal.Decimal,
+	gasPriceInGwei int64,
+) (signed *SignedTx, err error) {
 	rawRepayDebt := repayDebt.Mul(decimal.New(1, client.assets[auction.DebtSymbol].Decimal)).Floor()
 
-	return client.hydroContract.Send(sendTxParams, big.NewInt(0), "fillAuctionWithAmount", uint32(auction.ID), utils.DecimalToBigInt(rawRepayDebt))
+	return client.sign(gasPriceInGwei, func(tx *web3.SendTxParams) (string, string, error) {
+		return client.hydroContract.FillAuctionWithAmount(tx, uint32(auction.ID), utils.DecimalToBigInt(rawRepayDebt))
+	})
+}
+
+// ApproveDelegate lets the delegate match orders on behalf of the bidder address.
+// Hydro only honors delegates for order matching, auctions are always filled with the balance of the sender.
+func (client *BidderClient) ApproveDelegate(delegate string, gasPriceInGwei int64) (txHash string, err error) {
+	return client.send(gasPriceInGwei, func(tx *web3.SendTxParams) (string, string, error) {
+		return client.hydroContract.ApproveDelegate(tx, common.HexToAddress(delegate))
+	})
+}
+
+func (client *BidderClient) RevokeDelegate(delegate string, gasPriceInGwei int64) (txHash string, err error) {
+	return client.send(gasPriceInGwei, func(tx *web3.SendTxParams) (string, string, error) {
+		return client.hydroContract.RevokeDelegate(tx, common.HexToAddress(delegate))
+	})
+}
+
+// IsDelegate checks if the delegate is approved by the bidder address.
+func (client *BidderClient) IsDelegate(delegate string) (isDelegate bool, err error) {
+	return client.hydroContract.CanMatchOrdersFrom(&web3.CallOpts{From: delegate}, common.HexToAddress(client.bidderAddress))
 }
 
 func (client *BidderClient) GetFillAuctionRes(txHash string, auction *Auction) (
 	bidderRepay decimal.Decimal,
 	collateralForBidder decimal.Decimal,
 	gasUsed decimal.Decimal,
+	blockNumber int64,
 	err error) {
-	var receipt *web3.TransactionReceipt
-	for true {
-		receipt, err = client.web3.Rpc.EthGetTransactionReceipt(txHash)
-		if err != nil || receipt.BlockNumber == 0 {
-			time.Sleep(time.Second)
-		} else {
-			break
-		}
+	receipt, err := client.WaitForReceipt(txHash)
+	if err != nil {
+		return
 	}
+	bidderRepay, collateralForBidder, gasUsed, blockNumber = client.parseFillReceipt(receipt, auction)
+	return
+}
 
+// GetMinedFillAuctionRes is GetFillAuctionRes without waiting, mined is false if the transaction is pending or unknown.
+func (client *BidderClient) GetMinedFillAuctionRes(txHash string, auction *Auction) (
+	mined bool,
+	bidderRepay decimal.Decimal,
+	collateralForBidder decimal.Decimal,
+	gasUsed decimal.Decimal,
+	blockNumber int64,
+	err error) {
+	receipt, err := client.web3.Rpc.EthGetTransactionReceipt(txHash)
+	if err != nil || receipt == nil || receipt.BlockNumber == 0 {
+		return
+	}
+	mined = true
+	bidderRepay, collateralForBidder, gasUsed, blockNumber = client.parseFillReceipt(receipt, auction)
+	return
+}
+
+func (client *BidderClient) parseFillReceipt(receipt *web3.TransactionReceipt, auction *Auction) (
+	bidderRepay decimal.Decimal,
+	collateralForBidder decimal.Decimal,
+	gasUsed decimal.Decimal,
+	blockNumber int64) {
+	bidderRepay = decimal.Zero
+	collateralForBidder = decimal.Zero
 	gasUsed = decimal.New(int64(receipt.GasUsed), 0)
+	blockNumber = int64(receipt.BlockNumber)
 	if receipt.Status == "0x0" {
-		bidderRepay = decimal.Zero
-		collateralForBidder = decimal.Zero
 		return
 	}
 	for _, log := range receipt.Logs {
-		if log.Topics[0] == "0x42a553656a0da7239e70a4a3c864c1ac7d46d7968bfe2e1fb14f42dbb67135e8" {
-			bidderRepay = utils.HexString2Decimal(log.Data[130:194], -1*client.assets[auction.DebtSymbol].Decimal)
-			collateralForBidder = utils.HexString2Decimal(log.Data[194:258], -1*client.assets[auction.CollateralSymbol].Decimal)
+		if event, err := client.hydroContract.ParseFillAuction(log); err == nil {
+			bidderRepay, collateralForBidder = client.fillAmounts(event, auction)
 			return
 		}
 	}
@@ -110,52 +213,176 @@ func (client *BidderClient) GetFillAuctionRes(txHash string, auction *Auction) (
 	return
 }
 
-func (client *BidderClient) GetCurrentAuctionIDs() (auctionIDs []int64, err error) {
-	resp, err := client.hydroContract.Call("getCurrentAuctions")
+func (client *BidderClient) fillAmounts(event *hydro.FillAuctionEvent, auction *Auction) (bidderRepay decimal.Decimal, collateralForBidder decimal.Decimal) {
+	bidderRepay = decimal.NewFromBigInt(event.BidderRepayDebt, -1*client.assets[auction.DebtSymbol].Decimal)
+	collateralForBidder = decimal.NewFromBigInt(event.BidderCollateral, -1*client.assets[auction.CollateralSymbol].Decimal)
+	return
+}
+
+type FillEvent struct {
+	TxHash              string
+	BlockNumber         int64
+	Auction             *Auction
+	BidderRepay         decimal.Decimal
+	CollateralForBidder decimal.Decimal
+	GasUsed             decimal.Decimal
+	GasCost             decimal.Decimal // in ETH
+}
+
+// GetFillEvents returns the auctions filled by the bidder address from fromBlock on.
+func (client *BidderClient) GetFillEvents(fromBlock int64) (events []*FillEvent, err error) {
+	logs, err := client.web3.Rpc.EthGetLogs(web3.FilterParams{
+		FromBlock: fmt.Sprintf("0x%x", fromBlock),
+		ToBlock:   "latest",
+		Address:   []string{client.hydroContract.Address()},
+		Topics:    [][]string{{client.hydroContract.EventID("FillAuction")}},
+	})
 	if err != nil {
-		return nil, err
+		return
 	}
-	auctionNum := (len(resp)-2)/64 - 2
-	for i := 0; i < auctionNum; i++ {
-		auctionID, _ := utils.HexString2Int(resp[130+64*i : 194+64*i])
-		auctionIDs = append(auctionIDs, int64(auctionID))
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		var fill *hydro.FillAuctionEvent
+		if fill, err = client.hydroContract.ParseFillAuction(log); err != nil {
+			return
+		}
+		if !utils.IsAddressEqual(fill.Bidder.Hex(), client.bidderAddress) {
+			continue
+		}
+		var auction *Auction
+		if auction, err = client.GetSingleAuction(fill.AuctionID.Int64()); err != nil {
+			return
+		}
+		var receipt *web3.TransactionReceipt
+		if receipt, err = client.web3.Rpc.EthGetTransactionReceipt(log.TransactionHash); err != nil {
+			return
+		}
+		var tx *web3.Transaction
+		if tx, err = client.web3.Rpc.EthGetTransactionByHash(log.TransactionHash); err != nil {
+			return
+		}
+		gasUsed := decimal.New(int64(receipt.GasUsed), 0)
+		event := &FillEvent{
+			TxHash:      log.TransactionHash,
+			BlockNumber: int64(log.BlockNumber),
+			Auction:     auction,
+			GasUsed:     gasUsed,
+			GasCost:     gasUsed.Mul(decimal.NewFromBigInt(&tx.GasPrice, -18)),
+		}
+		event.BidderRepay, event.CollateralForBidder = client.fillAmounts(fill, auction)
+		events = append(events, event)
 	}
+	return
+}
 
+// GetFillBlocks returns the blocks any bidder filled an auction in, from fromBlock to toBlock inclusive.
+func (client *BidderClient) GetFillBlocks(fromBlock int64, toBlock int64) (blocks []int64, err error) {
+	logs, err := client.web3.Rpc.EthGetLogs(web3.FilterParams{
+		FromBlock: fmt.Sprintf("0x%x", fromBlock),
+		ToBlock:   fmt.Sprintf("0x%x", toBlock),
+		Address:   []string{client.hydroContract.Address()},
+		Topics:    [][]string{{client.hydroContract.EventID("FillAuction")}},
+	})
+	if err != nil {
+		return
+	}
+	for _, log := range logs {
+		if !log.Removed && (len(blocks) == 0 || blocks[len(blocks)-1] != int64(log.BlockNumber)) {
+			blocks = append(blocks, int64(log.BlockNumber))
+		}
+	}
 	return
 }
 
-func (client *BidderClient) GetSingleAuction(auctionID int64) (auction *Auction, err error) {
-	resp, err := client.hydroContract.Call("getAuctionDetails", uint32(auctionID))
+// GetBlockGasPrice returns the time of a block and the median gas price of its transactions, 0 if it has none
+func (client *BidderClient) GetBlockGasPrice(blockNum int64) (timestamp int64, gasPriceInGwei int64, err error) {
+	block, err := client.web3.Rpc.EthGetBlockByNumber(int(blockNum), true)
+	if err != nil {
+		return
+	}
+	var gasPrices []*big.Int
+	for i := range block.Transactions {
+		gasPrices = append(gasPrices, &block.Transactions[i].GasPrice)
+	}
+	sort.Slice(gasPrices, func(i, j int) bool { return gasPrices[i].Cmp(gasPrices[j]) < 0 })
+	if len(gasPrices) > 0 {
+		gasPriceInGwei = new(big.Int).Div(gasPrices[len(gasPrices)/2], big.NewInt(1000000000)).Int64()
+	}
+	return int64(block.Timestamp), gasPriceInGwei, nil
+}
+
+func (client *BidderClient) BlockNumber() (blockNumber int64, err error) {
+	number, err := client.web3.Rpc.EthBlockNumber()
+	return int64(number), err
+}
+
+// EthBalance is the ether of the bidder wallet paying the gas.
+func (client *BidderClient) EthBalance() (balance decimal.Decimal, err error) {
+	wei, err := client.web3.Rpc.EthGetBalance(client.bidderAddress, "latest")
 	if err != nil {
 		return
 	}
+	return decimal.NewFromBigInt(&wei, -18), nil
+}
+
+func (client *BidderClient) GetCurrentAuctionIDs() (auctionIDs []int64, err error) {
+	return client.getCurrentAuctionIDs(0)
+}
+
+func (client *BidderClient) getCurrentAuctionIDs(blockNum int64) (auctionIDs []int64, err error) {
+	ids, err := client.hydroContract.GetCurrentAuctions(&web3.CallOpts{BlockNum: blockNum})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		auctionIDs = append(auctionIDs, int64(id))
+	}
 
-	if len(resp) != 578 {
+	return
+}
+
+func (client *BidderClient) GetSingleAuction(auctionID int64) (auction *Auction, err error) {
+	return client.getSingleAuction(0, auctionID)
+}
+
+func (client *BidderClient) getSingleAuction(blockNum int64, auctionID int64) (auction *Auction, err error) {
+	details, err := client.hydroContract.GetAuctionDetails(&web3.CallOpts{BlockNum: blockNum}, uint32(auctionID))
+	if err == web3.ErrEmptyOutput {
 		err = utils.AuctionNotExist
+	}
+	if err != nil {
 		return
 	}
+	return client.newAuction(auctionID, details)
+}
 
-	debtAddress := "0x" + strings.ToLower(resp[2+64*2+24:2+64*3])
-	collateralAddress := "0x" + strings.ToLower(resp[2+64*3+24:2+64*4])
+// newAuction converts the details returned by the contract
+func (client *BidderClient) newAuction(auctionID int64, details hydro.AuctionDetails) (auction *Auction, err error) {
 
 	var debtSymbol string
 	var collateralSymbol string
 
 	for symbol, asset := range client.assets {
-		if utils.IsAddressEqual(asset.Address, debtAddress) {
+		if utils.IsAddressEqual(asset.Address, details.DebtAsset.Hex()) {
 			debtSymbol = symbol
 		}
-		if utils.IsAddressEqual(asset.Address, collateralAddress) {
+		if utils.IsAddressEqual(asset.Address, details.CollateralAsset.Hex()) {
 			collateralSymbol = symbol
 		}
 	}
+	if debtSymbol == "" || collateralSymbol == "" {
+		err = utils.AssetNotExist
+		return
+	}
 
-	availableCollateral := utils.HexString2Decimal(resp[2+64*5:2+64*6], -1*client.assets[collateralSymbol].Decimal)
-	availableDetb := utils.HexString2Decimal(resp[2+64*4:2+64*5], -1*client.assets[debtSymbol].Decimal)
+	availableCollateral := decimal.NewFromBigInt(details.LeftCollateralAmount, -1*client.assets[collateralSymbol].Decimal)
+	availableDetb := decimal.NewFromBigInt(details.LeftDebtAmount, -1*client.assets[debtSymbol].Decimal)
 	availableDetb = availableDetb.Mul(decimal.New(1, 0).Add(decimal.New(1, -5))) // the debt is growing while auction ongoing
 
-	ratio := utils.HexString2Decimal(resp[2+64*6:2+64*7], -18)
-	finished := utils.HexString2Decimal(resp[2+64*8:2+64*9], 0).IsPositive()
+	ratio := decimal.NewFromBigInt(details.Ratio, -18)
+	finished := details.Finished
 
 	if ratio.LessThan(decimal.New(1, 0)) {
 		availableCollateral = availableCollateral.Mul(ratio)
@@ -193,16 +420,37 @@ func (client *BidderClient) GetSingleAuction(auctionID int64) (auction *Auction,
 }
 
 func (client *BidderClient) GetAllAuctions() (auctions []*Auction, err error) {
-	auctionIDs, err := client.GetCurrentAuctionIDs()
+	return client.GetAllAuctionsAt(0)
+}
+
+// GetAllAuctionsAt returns the auctions as they were at a past block, the latest ones if blockNum is 0
+func (client *BidderClient) GetAllAuctionsAt(blockNum int64) (auctions []*Auction, err error) {
+	auctionIDs, err := client.getCurrentAuctionIDs(blockNum)
 	if err != nil {
 		return
 	}
+	// the details of every auction in one request
+	details := make([]hydro.AuctionDetails, len(auctionIDs))
+	calls := make([]*web3.ContractCall, len(auctionIDs))
+	for i, auctionID := range auctionIDs {
+		calls[i] = client.hydroContract.GetAuctionDetailsCall(&web3.CallOpts{BlockNum: blockNum}, &details[i], uint32(auctionID))
+	}
+	if err = client.hydroContract.BatchQuery(calls); err != nil {
+		return
+	}
 	auctions = []*Auction{}
-	for _, auctionID := range auctionIDs {
-		auction, err := client.GetSingleAuction(auctionID)
-		if err == nil {
-			auctions = append(auctions, auction)
+	// an auction failing to load is skipped for the block, the others are still bid on
+	for i, auctionID := range auctionIDs {
+		if calls[i].Error != nil {
+			logrus.Warnf("get auction #%d at block %d failed: %s", auctionID, blockNum, calls[i].Error.Error())
+			continue
+		}
+		auction, auctionErr := client.newAuction(auctionID, details[i])
+		if auctionErr != nil {
+			logrus.Warnf("auction #%d skipped: %s", auctionID, auctionErr.Error())
+			continue
 		}
+		auctions = append(auctions, auction)
 	}
 
 	return
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"auctionBidder/metrics"
+	"auctionBidder/simulator"
+	"auctionBidder/utils"
+	"auctionBidder/web3"
+	"bytes"
+	"errors"
+	"github.com/shopspring/decimal"
+	"strings"
+	"testing"
+)
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestFillAuctionAndHedge(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT",
+		[]*simulator.Level{{Price: d("199"), Amount: d("2")}, {Price: d("198"), Amount: d("10")}},
+		[]*simulator.Level{{Price: d("201"), Amount: d("10")}})
+	id := s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := web3.NewLocalSigner(privateKey)
+	s.SetBalance(signer.Address(), "USDT", d("1000"))
+	s.SetEthBalance(signer.Address(), d("1"))
+
+	ddex, err := NewDdexClient(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bidder, err := NewBidderClient(signer, ddex.Assets, ddex.Markets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auctions, err := bidder.GetAllAuctions()
+	if err != nil || len(auctions) != 1 {
+		t.Fatalf("unexpected auctions %v %v", auctions, err)
+	}
+	auction := auctions[0]
+	// the bidder gets 80% of the collateral, the debt is padded for the interest growing during the auction
+	if auction.ID != id || auction.TradingPair != "ETH-USDT" || auction.AvailableCollateral.String() != "2.4" || auction.AvailableDebt.String() != "450.0045" {
+		t.Errorf("unexpected auction %+v", auction)
+	}
+	startBlock := s.BlockNumber()
+
+	signed, err := bidder.SignFillAuction(auction, d("300"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bidder.SendRawTransaction(signed.RawTx); err != nil {
+		t.Fatal(err)
+	}
+	repay, collateral, gasUsed, blockNumber, err := bidder.GetFillAuctionRes(signed.TxHash, auction)
+	if repay.String() != "300" || collateral.String() != "1.6" || !gasUsed.IsPositive() || blockNumber != startBlock+1 || err != nil {
+		t.Errorf("unexpected fill: repay %s collateral %s gas %s block %d %v", repay, collateral, gasUsed, blockNumber, err)
+	}
+	if _, err = bidder.SendRawTransaction(signed.RawTx); err == nil {
+		t.Errorf("a transaction can't be sent twice")
+	}
+
+	past, _ := bidder.GetAllAuctionsAt(startBlock)
+	latest, _ := bidder.GetSingleAuction(id)
+	if len(past) != 1 || !past[0].AvailableCollateral.Equal(auction.AvailableCollateral) || latest.AvailableCollateral.String() != "0.8" {
+		t.Errorf("auction not updated by the fill: %+v %+v", past, latest)
+	}
+	events, err := bidder.GetFillEvents(startBlock)
+	if err != nil || len(events) != 1 || events[0].TxHash != signed.TxHash || !events[0].GasCost.Equal(gasUsed.Mul(d("0.00000001"))) {
+		t.Errorf("unexpected fill events %+v %v", events, err)
+	}
+
+	// 1.6ETH sold into the best bid at 199, minus 0.1% fee
+	order, sold, received, err := ddex.PromisedMarketSellAsset(auction.TradingPair, "ETH", collateral, d("0.05"))
+	if err != nil || sold.String() != "1.6" || received.String() != "318.4" || order.Status != utils.ORDERCLOSE {
+		t.Errorf("unexpected hedge %+v sold %s received %s %v", order, sold, received, err)
+	}
+	if receive, _ := ddex.QuerySellAssetReceiveAmount(auction.TradingPair, "ETH", d("1")); receive.String() != "198.4" {
+		t.Errorf("hedge should take the orderbook: %s", receive)
+	}
+	inventory, err := ddex.GetInventory()
+	if err != nil || inventory["USDT"].Free.String() != "1018.0816" || !inventory["ETH"].Free.IsZero() {
+		t.Errorf("unexpected inventory %+v %v", inventory, err)
+	}
+	orders, _ := ddex.GetOrders(auction.TradingPair, 0)
+	if len(orders) != 1 || orders[0].Id != order.Id || orders[0].TakerFeeRate.String() != "0.001" {
+		t.Errorf("unexpected orders %+v", orders)
+	}
+
+	// no ETH left, ddex keeps rejecting the hedge and it gives up
+	ddex.HedgeAttempts = 2
+	if _, _, _, err = ddex.PromisedMarketSellAsset(auction.TradingPair, "ETH", collateral, d("0.05")); err == nil {
+		t.Errorf("a hedge rejected by ddex should fail")
+	}
+}
+
+func TestBatchedReads(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddAsset("DAI", 18, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.AddMarket("ETH", "DAI", d("0.001"))
+	for i := 0; i < 3; i++ {
+		s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+	}
+	s.AddAuction("DAI", "ETH", d("100"), d("1"), d("0.5"))
+
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := web3.NewLocalSigner(privateKey)
+	s.SetBalance(signer.Address(), "USDT", d("1000"))
+	s.SetBalance(signer.Address(), "DAI", d("20"))
+	ddex, _ := NewDdexClient(signer)
+	bidder, _ := NewBidderClient(signer, ddex.Assets, ddex.Markets)
+
+	// the auction ids, then the details of all of them
+	requests := s.NodeRequests()
+	auctions, err := bidder.GetAllAuctions()
+	if err != nil || len(auctions) != 4 || s.NodeRequests()-requests != 2 {
+		t.Fatalf("%d auctions in %d requests %v", len(auctions), s.NodeRequests()-requests, err)
+	}
+	if auctions[3].TradingPair != "ETH-DAI" || auctions[3].AvailableCollateral.String() != "0.5" {
+		t.Errorf("unexpected auction %+v", auctions[3])
+	}
+
+	requests = s.NodeRequests()
+	inventory, err := ddex.GetInventory()
+	if err != nil || s.NodeRequests()-requests != 1 {
+		t.Fatalf("inventory in %d requests %v", s.NodeRequests()-requests, err)
+	}
+	if inventory["USDT"].Free.String() != "1000" || inventory["DAI"].Free.String() != "20" || !inventory["ETH"].Free.IsZero() {
+		t.Errorf("unexpected inventory %+v", inventory)
+	}
+}
+
+func TestGetOrdersPages(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := web3.NewLocalSigner(privateKey)
+	s.SetBalance(signer.Address(), "USDT", d("1000"))
+	ddex, _ := NewDdexClient(signer)
+
+	for i := 0; i < 150; i++ {
+		if _, err := ddex.CreateLimitOrder("ETH-USDT", d("100"), d("0.01"), utils.BUY, true, 3600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if orders, err := ddex.GetOrders("ETH-USDT", 0); err != nil || len(orders) != 150 {
+		t.Errorf("%d orders of 150 %v", len(orders), err)
+	}
+	// the first page is older than since
+	if orders, err := ddex.GetOrders("ETH-USDT", utils.MillisecondTimestamp()+1); err != nil || len(orders) != 100 {
+		t.Errorf("%d orders, expect a page of 100 %v", len(orders), err)
+	}
+}
+
+func TestDelegate(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.AddAuction("USDT", "ETH", d("450"), d("3"), d("0.8"))
+
+	ownerKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	delegateKey, _ := utils.NewPrivateKeyByHex("B7A0C9D2786FC4DD080EA5D619D36771AEB0C8C26C290AFD3451B92BA2B7BC2C")
+	owner, delegate := web3.NewLocalSigner(ownerKey), web3.NewLocalSigner(delegateKey)
+	s.SetBalance(owner.Address(), "USDT", d("1000"))
+	s.SetEthBalance(owner.Address(), d("1"))
+	s.SetEthBalance(delegate.Address(), d("1"))
+	ownerClient, _ := NewBidderClient(owner, nil, nil)
+
+	if isDelegate, err := ownerClient.IsDelegate(delegate.Address()); err != nil || isDelegate {
+		t.Errorf("not granted yet: %t %v", isDelegate, err)
+	}
+	txHash, err := ownerClient.ApproveDelegate(delegate.Address(), 10)
+	if receipt, _ := ownerClient.WaitForReceipt(txHash); err != nil || receipt.Status != "0x1" {
+		t.Fatalf("grant failed %+v %v", receipt, err)
+	}
+	if isDelegate, err := ownerClient.IsDelegate(delegate.Address()); err != nil || !isDelegate {
+		t.Errorf("delegate not granted: %t %v", isDelegate, err)
+	}
+
+	// a delegate fills auctions with its own balance, never with the balance of the owner
+	ddex, _ := NewDdexClient(delegate)
+	delegateClient, _ := NewBidderClient(delegate, ddex.Assets, ddex.Markets)
+	auctions, _ := delegateClient.GetAllAuctions()
+	signed, err := delegateClient.SignFillAuction(auctions[0], d("100"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegateClient.SendRawTransaction(signed.RawTx)
+	if _, collateral, _, _, err := delegateClient.GetFillAuctionRes(signed.TxHash, auctions[0]); err != nil || !collateral.IsZero() {
+		t.Errorf("a delegate without funds should not fill the auction: %s %v", collateral, err)
+	}
+	if usdt := s.Balance(owner.Address(), "USDT"); usdt.String() != "1000" {
+		t.Errorf("the owner paid %sUSDT for the bid of a delegate", d("1000").Sub(usdt))
+	}
+
+	txHash, err = ownerClient.RevokeDelegate(delegate.Address(), 10)
+	if receipt, _ := ownerClient.WaitForReceipt(txHash); err != nil || receipt.Status != "0x1" {
+		t.Fatalf("revoke failed %+v %v", receipt, err)
+	}
+	if isDelegate, err := ownerClient.IsDelegate(delegate.Address()); err != nil || isDelegate {
+		t.Errorf("delegate not revoked: %t %v", isDelegate, err)
+	}
+}
+
+// a remote signer rejecting the requests once fail is set
+type rejectingSigner struct {
+	web3.Signer
+	fail bool
+}
+
+func (s *rejectingSigner) SignPersonalMessage(message []byte) ([]byte, error) {
+	if s.fail {
+		return nil, errors.New("request denied")
+	}
+	return s.Signer.SignPersonalMessage(message)
+}
+
+func TestOrderSignatureRejected(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+	s.AddAsset("USDT", 6, d("1"))
+	s.AddMarket("ETH", "USDT", d("0.001"))
+	s.SetOrderbook("ETH-USDT",
+		[]*simulator.Level{{Price: d("199"), Amount: d("10")}},
+		[]*simulator.Level{{Price: d("201"), Amount: d("10")}})
+
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := &rejectingSigner{Signer: web3.NewLocalSigner(privateKey)}
+	s.SetBalance(signer.Address(), "ETH", d("1"))
+	ddex, _ := NewDdexClient(signer)
+	// the authentication is signed before the signer rejects
+	if _, err := ddex.GetOrders("ETH-USDT", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	signer.fail = true
+	if _, err := ddex.CreateMarketOrder("ETH-USDT", d("190"), d("1"), utils.SELL); err == nil || !strings.Contains(err.Error(), "request denied") {
+		t.Errorf("expect the signer error, got %v", err)
+	}
+	if orders := s.Orders(signer.Address()); len(orders) != 0 {
+		t.Errorf("order placed without a signature: %+v", orders)
+	}
+}
+
+func TestPriceRequestsObserved(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	s.AddAsset("ETH", 18, d("200"))
+
+	if prices, err := GetAssetUSDPrices(simulator.DdexUrl); err != nil || prices["ETH"].String() != "200" {
+		t.Fatalf("unexpected prices %v %v", prices, err)
+	}
+	var scrape bytes.Buffer
+	metrics.DefaultRegistry.Write(&scrape)
+	if !strings.Contains(scrape.String(), `auction_bidder_ddex_duration_seconds_count{method="GET",endpoint="assets"}`) {
+		t.Errorf("the price request is missing in the ddex metrics")
+	}
+}
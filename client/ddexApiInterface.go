@@ -108,7 +108,7 @@ type IOrder struct {
 	} `json:"data"`
 }
 
-type IAllPendingOrders struct {
+type IOrders struct {
 	Status int    `json:"status"`
 	Desc   string `json:"desc"`
 	Data   struct {
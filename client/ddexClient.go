@@ -1,6 +1,8 @@
 package client
 
 import (
+	"auctionBidder/hydro"
+	"auctionBidder/metrics"
 	"auctionBidder/utils"
 	"auctionBidder/web3"
 	"encoding/hex"
@@ -10,9 +12,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -31,6 +35,23 @@ type OrderRes struct {
 	AvailableAmount decimal.Decimal
 	FilledAmount    decimal.Decimal
 	AvgPrice        decimal.Decimal
+	TakerFeeRate    decimal.Decimal
+	GasFeeAmount    decimal.Decimal
+	MarketID        string
+	CreatedAt       int64 // unix milliseconds
+}
+
+// SoldAndReceived converts the filled amount of a market order to the amounts of the sold and received asset.
+// Market buy orders are sized in the quote asset.
+func (order *OrderRes) SoldAndReceived() (sellAmount decimal.Decimal, receiveAmount decimal.Decimal) {
+	sellAmount = order.FilledAmount
+	receiveAmount = decimal.Zero
+	if order.Side == utils.SELL {
+		receiveAmount = order.FilledAmount.Mul(order.AvgPrice)
+	} else if order.AvgPrice.IsPositive() {
+		receiveAmount = order.FilledAmount.Div(order.AvgPrice)
+	}
+	return
 }
 
 type Balance struct {
@@ -59,35 +80,40 @@ type DdexClient struct {
 	Address       string
 	Assets        map[string]*Asset  // symbol -> Asset
 	Markets       map[string]*Market // "ETH-DAI" -> Market
-	hydroContract *web3.Contract
-	privateKey    string
+	hydroContract *hydro.Hydro
+	signer        web3.Signer
+	signMu        sync.Mutex // the client is shared by the bot and the api
 	signCache     string
 	lastSignTime  int64
 	baseUrl       string
+
+	HedgeAttempts int // PromisedMarketSellAsset gives up after it, ddex may keep rejecting the order
 }
 
-func NewDdexClient(privateKey string) (client *DdexClient, err error) {
+func NewDdexClient(signer web3.Signer) (client *DdexClient, err error) {
 	ethereumNodeUrl := os.Getenv("ETHEREUM_NODE_URL")
 	ddexBaseUrl := os.Getenv("DDEX_URL")
 	hydroContractAddress := os.Getenv("HYDRO_CONTRACT_ADDRESS")
 
 	web3 := web3.NewWeb3(ethereumNodeUrl)
-	address, err := web3.AddPrivateKey(privateKey)
-	if err != nil {
-		return
+	var address string
+	if signer != nil { // read only without a signer
+		address = web3.AddSigner(signer)
 	}
-	contract, err := web3.NewContract(utils.HydroAbi, hydroContractAddress)
+	contract, err := hydro.NewHydro(web3, hydroContractAddress)
 	if err != nil {
 		return
 	}
 
 	// get market meta data
 	var dataContainer IMarkets
+	start := time.Now()
 	resp, err := utils.Get(
 		utils.JoinUrlPath(ddexBaseUrl, fmt.Sprintf("markets")),
 		"",
 		utils.EmptyKeyPairList,
 		[]utils.KeyPair{{"Content-Type", "application/json"}})
+	observeDdex("GET", "markets", start, &err)
 	if err != nil {
 		logrus.Error("call " + utils.JoinUrlPath(ddexBaseUrl, fmt.Sprintf("markets")) + " failed")
 		return
@@ -118,74 +144,110 @@ func NewDdexClient(privateKey string) (client *DdexClient, err error) {
 	}
 
 	client = &DdexClient{
-		address,
-		assets,
-		markets,
-		contract,
-		privateKey,
-		"",
-		0,
-		ddexBaseUrl,
+		Address:       address,
+		Assets:        assets,
+		Markets:       markets,
+		hydroContract: contract,
+		signer:        signer,
+		baseUrl:       ddexBaseUrl,
+		HedgeAttempts: 10,
 	}
 
 	return
 }
 
-func (client *DdexClient) updateSignCache() {
+func (client *DdexClient) updateSignCache() string {
+	client.signMu.Lock()
+	defer client.signMu.Unlock()
 	now := utils.MillisecondTimestamp()
 	if client.lastSignTime < now-200000 {
 		messageStr := "HYDRO-AUTHENTICATION@" + strconv.Itoa(int(now))
-		signRes, _ := utils.PersonalSign([]byte(messageStr), client.privateKey)
+		signRes, err := client.signer.SignPersonalMessage([]byte(messageStr))
+		if err != nil {
+			logrus.Errorf("sign ddex authentication failed: %s", err.Error())
+			return client.signCache
+		}
 		client.signCache = fmt.Sprintf("%s#%s#0x%x", strings.ToLower(client.Address), messageStr, signRes)
 		client.lastSignTime = now
 	}
+	return client.signCache
 }
 
-func (client *DdexClient) signOrderId(orderId string) string {
-	orderIdBytes, _ := hex.DecodeString(strings.TrimPrefix(orderId, "0x"))
-	signature, _ := utils.PersonalSign(orderIdBytes, client.privateKey)
-	return "0x" + hex.EncodeToString(signature)
+// a remote signer may time out or reject the request, the order is never placed without a signature
+func (client *DdexClient) signOrderId(orderId string) (signature string, err error) {
+	orderIdBytes, err := hex.DecodeString(strings.TrimPrefix(orderId, "0x"))
+	if err != nil {
+		return
+	}
+	signatureBytes, err := client.signer.SignPersonalMessage(orderIdBytes)
+	if err != nil {
+		return "", fmt.Errorf("sign order %s failed: %s", orderId, err.Error())
+	}
+	return "0x" + hex.EncodeToString(signatureBytes), nil
 }
 
-func (client *DdexClient) get(path string, params []utils.KeyPair) (string, error) {
-	client.updateSignCache()
+func (client *DdexClient) get(path string, params []utils.KeyPair) (resp string, err error) {
+	authentication := client.updateSignCache()
+	defer observeDdex("GET", path, time.Now(), &err)
 	return utils.Get(
 		utils.JoinUrlPath(client.baseUrl, path),
 		"",
 		params,
 		[]utils.KeyPair{
-			{"Hydro-Authentication", client.signCache},
+			{"Hydro-Authentication", authentication},
 			{"Content-Type", "application/json"},
 		},
 	)
 }
 
-func (client *DdexClient) post(path string, body string, params []utils.KeyPair) (string, error) {
-	client.updateSignCache()
+func (client *DdexClient) post(path string, body string, params []utils.KeyPair) (resp string, err error) {
+	authentication := client.updateSignCache()
+	defer observeDdex("POST", path, time.Now(), &err)
 	return utils.Post(
 		utils.JoinUrlPath(client.baseUrl, path),
 		body,
 		params,
 		[]utils.KeyPair{
-			{"Hydro-Authentication", client.signCache},
+			{"Hydro-Authentication", authentication},
 			{"Content-Type", "application/json"},
 		},
 	)
 }
 
-func (client *DdexClient) delete(path string, params []utils.KeyPair) (string, error) {
-	client.updateSignCache()
+func (client *DdexClient) delete(path string, params []utils.KeyPair) (resp string, err error) {
+	authentication := client.updateSignCache()
+	defer observeDdex("DELETE", path, time.Now(), &err)
 	return utils.Delete(
 		utils.JoinUrlPath(client.baseUrl, path),
 		"",
 		params,
 		[]utils.KeyPair{
-			{"Hydro-Authentication", client.signCache},
+			{"Hydro-Authentication", authentication},
 			{"Content-Type", "application/json"},
 		},
 	)
 }
 
+func observeDdex(method string, path string, start time.Time, err *error) {
+	endpoint := ddexEndpoint(path)
+	metrics.DdexDuration.Observe(time.Since(start).Seconds(), method, endpoint)
+	if *err != nil {
+		metrics.DdexErrors.Inc(method, endpoint)
+	}
+}
+
+// order ids and markets are replaced by placeholders to keep the metric labels bounded
+func ddexEndpoint(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 2 && parts[0] == "orders" && parts[1] != "build" {
+		parts[1] = ":orderId"
+	}
+	if len(parts) >= 2 && parts[0] == "markets" {
+		parts[1] = ":marketId"
+	}
+	return strings.Join(parts, "/")
+}
+
 func (client *DdexClient) buildUnsignedOrder(
 	tradingPair string,
 	price decimal.Decimal,
@@ -230,10 +292,14 @@ func (client *DdexClient) buildUnsignedOrder(
 }
 
 func (client *DdexClient) placeOrder(orderId string) (res *OrderRes, err error) {
+	signature, err := client.signOrderId(orderId)
+	if err != nil {
+		return
+	}
 	var body = struct {
 		OrderId   string `json:"orderId"`
 		Signature string `json:"signature"`
-	}{orderId, client.signOrderId(orderId)}
+	}{orderId, signature}
 	bodyBytes, _ := json.Marshal(body)
 	resp, err := client.post("orders", string(bodyBytes), utils.EmptyKeyPairList)
 	if err != nil {
@@ -296,21 +362,19 @@ func (client *DdexClient) MarketSellAsset(
 	amount decimal.Decimal,
 	maxSlippage decimal.Decimal,
 ) (
-	orderId string,
+	order *OrderRes,
 	sellAmount decimal.Decimal,
 	receiveAmount decimal.Decimal,
 	err error,
 ) {
-	orderId = "0x0"
 	sellAmount = decimal.Zero
 	receiveAmount = decimal.Zero
 	_, _, midPrice, err := client.GetMarketPrice(tradingPair)
 	if err != nil {
 		return
 	}
-	var orderRes *OrderRes
 	if assetSymbol == strings.Split(tradingPair, "-")[0] {
-		orderRes, err = client.CreateMarketOrder(
+		order, err = client.CreateMarketOrder(
 			tradingPair,
 			midPrice.Mul(decimal.New(1, 0).Sub(maxSlippage)),
 			amount,
@@ -318,13 +382,9 @@ func (client *DdexClient) MarketSellAsset(
 		)
 		if err != nil {
 			return
-		} else {
-			orderId = orderRes.Id
-			sellAmount = orderRes.FilledAmount
-			receiveAmount = orderRes.FilledAmount.Mul(orderRes.AvgPrice)
 		}
 	} else {
-		orderRes, err = client.CreateMarketOrder(
+		order, err = client.CreateMarketOrder(
 			tradingPair,
 			midPrice.Mul(decimal.New(1, 0).Add(maxSlippage)),
 			amount,
@@ -332,35 +392,34 @@ func (client *DdexClient) MarketSellAsset(
 		)
 		if err != nil {
 			return
-		} else {
-			orderId = orderRes.Id
-			sellAmount = orderRes.FilledAmount
-			receiveAmount = orderRes.FilledAmount.Div(orderRes.AvgPrice)
 		}
 	}
+	sellAmount, receiveAmount = order.SoldAndReceived()
 
 	return
 }
 
+// PromisedMarketSellAsset retries the market order a second apart, err is the error of the last of HedgeAttempts.
 func (client *DdexClient) PromisedMarketSellAsset(
 	tradingPair string,
 	assetSymbol string,
 	amount decimal.Decimal,
 	maxSlippage decimal.Decimal,
 ) (
-	orderId string,
+	order *OrderRes,
 	sellAmount decimal.Decimal,
 	receiveAmount decimal.Decimal,
 	err error,
 ) {
-	for {
-		orderId, sellAmount, receiveAmount, err = client.MarketSellAsset(tradingPair, assetSymbol, amount, maxSlippage)
-		if err != nil {
-			time.Sleep(time.Second)
-		} else {
+	for attempt := 1; true; attempt++ {
+		order, sellAmount, receiveAmount, err = client.MarketSellAsset(tradingPair, assetSymbol, amount, maxSlippage)
+		if err == nil || attempt >= client.HedgeAttempts {
 			return
 		}
+		logrus.Warnf("market sell %s%s at %s failed, attempt %d of %d: %s", amount.String(), assetSymbol, tradingPair, attempt, client.HedgeAttempts, err.Error())
+		time.Sleep(time.Second)
 	}
+	return
 }
 
 func (client *DdexClient) CancelOrder(orderId string) error {
@@ -401,6 +460,10 @@ func (client *DdexClient) parseDdexOrderResp(orderInfo IOrderResp) *OrderRes {
 	orderData.AvailableAmount, _ = decimal.NewFromString(orderInfo.AvailableAmount)
 	orderData.Price, _ = decimal.NewFromString(orderInfo.Price)
 	orderData.AvgPrice, _ = decimal.NewFromString(orderInfo.AveragePrice)
+	orderData.TakerFeeRate, _ = decimal.NewFromString(orderInfo.TakerFeeRate)
+	orderData.GasFeeAmount, _ = decimal.NewFromString(orderInfo.GasFeeAmount)
+	orderData.MarketID = orderInfo.MarketID
+	orderData.CreatedAt = orderInfo.CreatedAt
 	pendingAmount, _ := decimal.NewFromString(orderInfo.PendingAmount)
 	confirmedAmount, _ := decimal.NewFromString(orderInfo.ConfirmedAmount)
 	orderData.FilledAmount = pendingAmount.Add(confirmedAmount)
@@ -434,15 +497,54 @@ func (client *DdexClient) GetOrder(orderId string) (res *OrderRes, err error) {
 	return
 }
 
+// GetOrders returns the orders of the market created since the unix milliseconds, pending or not, newest first.
+// The orders are paged, the last page may have a few older ones.
+func (client *DdexClient) GetOrders(tradingPair string, since int64) (orders []*OrderRes, err error) {
+	for page := 1; true; page++ {
+		var resp string
+		resp, err = client.get("orders", []utils.KeyPair{
+			{Key: "marketId", Value: tradingPair},
+			{Key: "status", Value: "all"},
+			{Key: "page", Value: strconv.Itoa(page)},
+			{Key: "perPage", Value: "100"},
+		})
+		if err != nil {
+			return
+		}
+		var dataContainer IOrders
+		json.Unmarshal([]byte(resp), &dataContainer)
+		if dataContainer.Desc != "success" {
+			err = errors.New(dataContainer.Desc)
+			return
+		}
+		for _, orderInfo := range dataContainer.Data.Orders {
+			orders = append(orders, client.parseDdexOrderResp(orderInfo))
+		}
+		if len(dataContainer.Data.Orders) == 0 || page >= dataContainer.Data.TotalPages || orders[len(orders)-1].CreatedAt < since {
+			return
+		}
+	}
+	return
+}
+
 func (client *DdexClient) GetInventory() (inventory Inventory, err error) {
-	inventory = map[string]*Balance{}
+	// the balances of every asset in one request
+	var symbols []string
+	var calls []*web3.ContractCall
+	rawAmounts := make([]*big.Int, len(client.Assets))
 	for symbol, asset := range client.Assets {
-		var amountHex string
-		amountHex, err = client.hydroContract.Call("balanceOf", common.HexToAddress(asset.Address), common.HexToAddress(client.Address))
-		if err != nil {
+		calls = append(calls, client.hydroContract.BalanceOfCall(nil, &rawAmounts[len(calls)], common.HexToAddress(asset.Address), common.HexToAddress(client.Address)))
+		symbols = append(symbols, symbol)
+	}
+	if err = client.hydroContract.BatchQuery(calls); err != nil {
+		return
+	}
+	inventory = map[string]*Balance{}
+	for i, symbol := range symbols {
+		if err = calls[i].Error; err != nil {
 			return
 		}
-		amount := utils.HexString2Decimal(amountHex, -1*asset.Decimal)
+		amount := decimal.NewFromBigInt(rawAmounts[i], -1*client.Assets[symbol].Decimal)
 		inventory[symbol] = &Balance{amount, decimal.Zero, amount}
 	}
 
@@ -463,6 +565,10 @@ func (client *DdexClient) GetInventory() (inventory Inventory, err error) {
 			inventory[lockedBalance.Symbol].Free = inventory[lockedBalance.Symbol].Total.Sub(inventory[lockedBalance.Symbol].Lock)
 		}
 	}
+	for symbol, balance := range inventory {
+		free, _ := balance.Free.Float64()
+		metrics.FreeBalance.Set(free, client.Address, symbol)
+	}
 
 	return
 }
@@ -492,11 +598,19 @@ func (client *DdexClient) GetMarketPrice(tradingPair string) (
 	return
 }
 
-func (client *DdexClient) QuerySellAssetReceiveAmount(
-	tradingPair string,
-	assetSymbol string,
-	payAmount decimal.Decimal,
-) (receiveAmount decimal.Decimal, err error) {
+// OrderbookLevel is the amount of all orders at a price.
+type OrderbookLevel struct {
+	Price  decimal.Decimal `json:"price"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Orderbook is the level 2 orderbook of a market, best prices first.
+type Orderbook struct {
+	Bids []*OrderbookLevel `json:"bids"`
+	Asks []*OrderbookLevel `json:"asks"`
+}
+
+func (client *DdexClient) GetOrderbook(tradingPair string) (orderbook *Orderbook, err error) {
 	resp, err := client.get(fmt.Sprintf("markets/%s/orderbook", tradingPair), []utils.KeyPair{{"level", "2"}})
 	if err != nil {
 		return
@@ -508,47 +622,89 @@ func (client *DdexClient) QuerySellAssetReceiveAmount(
 		return
 	}
 
+	orderbook = &Orderbook{Bids: []*OrderbookLevel{}, Asks: []*OrderbookLevel{}}
+	for _, bid := range dataContainer.Data.OrderBook.Bids {
+		price, _ := decimal.NewFromString(bid.Price)
+		amount, _ := decimal.NewFromString(bid.Amount)
+		orderbook.Bids = append(orderbook.Bids, &OrderbookLevel{price, amount})
+	}
+	for _, ask := range dataContainer.Data.OrderBook.Asks {
+		price, _ := decimal.NewFromString(ask.Price)
+		amount, _ := decimal.NewFromString(ask.Amount)
+		orderbook.Asks = append(orderbook.Asks, &OrderbookLevel{price, amount})
+	}
+	return
+}
+
+// SellReceiveAmount simulates a market order selling payAmount of the base asset into the bids,
+// or of the quote asset into the asks if sellQuote is true.
+func (orderbook *Orderbook) SellReceiveAmount(sellQuote bool, payAmount decimal.Decimal) (receiveAmount decimal.Decimal, err error) {
 	receiveAmount = decimal.Zero
-	if assetSymbol == client.Markets[tradingPair].Quote.Symbol {
-		for _, ask := range dataContainer.Data.OrderBook.Asks {
-			price, _ := decimal.NewFromString(ask.Price)
-			amount, _ := decimal.NewFromString(ask.Amount)
-			if price.Mul(amount).GreaterThanOrEqual(payAmount) {
-				receiveAmount = receiveAmount.Add(payAmount.Div(price))
+	if sellQuote {
+		for _, ask := range orderbook.Asks {
+			if ask.Price.Mul(ask.Amount).GreaterThanOrEqual(payAmount) {
+				receiveAmount = receiveAmount.Add(payAmount.Div(ask.Price))
 				payAmount = decimal.Zero
 				break
 			} else {
-				receiveAmount = receiveAmount.Add(amount)
-				payAmount = payAmount.Sub(price.Mul(amount))
+				receiveAmount = receiveAmount.Add(ask.Amount)
+				payAmount = payAmount.Sub(ask.Price.Mul(ask.Amount))
 			}
 		}
-		if payAmount.IsPositive() {
-			err = utils.OrderbookDepthNotEnough
-		}
 	} else {
-		for _, bid := range dataContainer.Data.OrderBook.Bids {
-			price, _ := decimal.NewFromString(bid.Price)
-			amount, _ := decimal.NewFromString(bid.Amount)
-			if amount.GreaterThanOrEqual(payAmount) {
-				receiveAmount = receiveAmount.Add(payAmount.Mul(price))
+		for _, bid := range orderbook.Bids {
+			if bid.Amount.GreaterThanOrEqual(payAmount) {
+				receiveAmount = receiveAmount.Add(payAmount.Mul(bid.Price))
 				payAmount = decimal.Zero
 				break
 			} else {
-				receiveAmount = receiveAmount.Add(amount.Mul(price))
-				payAmount = payAmount.Sub(amount)
+				receiveAmount = receiveAmount.Add(bid.Amount.Mul(bid.Price))
+				payAmount = payAmount.Sub(bid.Amount)
 			}
 		}
-		if payAmount.IsPositive() {
-			err = utils.OrderbookDepthNotEnough
-		}
+	}
+	if payAmount.IsPositive() {
+		err = utils.OrderbookDepthNotEnough
 	}
 
 	return
 }
 
+func (client *DdexClient) QuerySellAssetReceiveAmount(
+	tradingPair string,
+	assetSymbol string,
+	payAmount decimal.Decimal,
+) (receiveAmount decimal.Decimal, err error) {
+	orderbook, err := client.GetOrderbook(tradingPair)
+	if err != nil {
+		return
+	}
+	return orderbook.SellReceiveAmount(assetSymbol == client.Markets[tradingPair].Quote.Symbol, payAmount)
+}
+
 func (client *DdexClient) GetAssetUSDPrice(assetSymbol string) (price decimal.Decimal, err error) {
 	price = decimal.New(-1, 0)
-	resp, err := client.get("assets", utils.EmptyKeyPairList)
+	prices, err := GetAssetUSDPrices(client.baseUrl)
+	if err != nil {
+		return
+	}
+	if p, ok := prices[assetSymbol]; ok {
+		price = p
+	}
+	if price.LessThanOrEqual(decimal.Zero) {
+		err = errors.New("not find asset usd price")
+	}
+	return
+}
+
+// GetAssetUSDPrices returns the oracle usd price of every ddex asset, it doesn't need an account
+func GetAssetUSDPrices(ddexBaseUrl string) (prices map[string]decimal.Decimal, err error) {
+	defer observeDdex("GET", "assets", time.Now(), &err)
+	resp, err := utils.Get(
+		utils.JoinUrlPath(ddexBaseUrl, "assets"),
+		"",
+		utils.EmptyKeyPairList,
+		[]utils.KeyPair{{Key: "Content-Type", Value: "application/json"}})
 	if err != nil {
 		return
 	}
@@ -558,13 +714,12 @@ func (client *DdexClient) GetAssetUSDPrice(assetSymbol string) (price decimal.De
 		err = errors.New(dataContainer.Desc)
 		return
 	}
+	prices = map[string]decimal.Decimal{}
 	for _, asset := range dataContainer.Data.Assets {
-		if assetSymbol == asset.Symbol {
-			price, _ = decimal.NewFromString(asset.OracleUSDPrice)
+		price, parseErr := decimal.NewFromString(asset.OracleUSDPrice)
+		if parseErr == nil && price.IsPositive() {
+			prices[asset.Symbol] = price
 		}
 	}
-	if price.LessThanOrEqual(decimal.Zero) {
-		err = errors.New("not find asset usd price")
-	}
 	return
 }
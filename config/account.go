@@ -0,0 +1,149 @@
+package config
+
+import (
+	"auctionBidder/utils"
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"golang.org/x/crypto/ssh/terminal"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// the account configured by KEYSTORE_PATH, PRIVATE_KEY or SIGNER_URL
+const DefaultAccount = "default"
+
+// AccountParams is an additional account to bid with, listed in ACCOUNTS.
+type AccountParams struct {
+	Name                 string  `json:"NAME"`
+	KeystorePath         string  `json:"KEYSTORE_PATH"`
+	KeystorePasswordFile string  `json:"KEYSTORE_PASSWORD_FILE"`
+	SignerUrl            string  `json:"SIGNER_URL"`
+	SignerAddress        string  `json:"SIGNER_ADDRESS"`
+	MaxBidValueUSD       *string `json:"MAX_BID_VALUE_USD"`
+}
+
+// Account is a bidding account with its own balances and nonces.
+type Account struct {
+	Name           string
+	Address        string
+	PrivateKey     *ecdsa.PrivateKey // nil when SignerUrl is set
+	SignerUrl      string
+	MaxBidValueUSD decimal.Decimal // collateral usd value of a single bid, zero means no limit
+}
+
+func parseAccounts(value string) (accounts []*AccountParams, err error) {
+	decoder := json.NewDecoder(bytes.NewBufferString(value))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&accounts); err != nil {
+		return
+	}
+	names := map[string]bool{DefaultAccount: true}
+	for i, p := range accounts {
+		if p == nil {
+			return nil, fmt.Errorf("account %d must be an object", i)
+		}
+		if p.Name == "" {
+			return nil, fmt.Errorf("account %d: missing NAME", i)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("duplicate account name %s", p.Name)
+		}
+		names[p.Name] = true
+
+		if p.SignerUrl != "" {
+			if err = parseHttpUrl(p.SignerUrl); err != nil {
+				return nil, fmt.Errorf("%s: SIGNER_URL=%q: %s", p.Name, p.SignerUrl, err.Error())
+			}
+			if !common.IsHexAddress(p.SignerAddress) {
+				return nil, fmt.Errorf("%s: SIGNER_ADDRESS=%q: invalid address", p.Name, p.SignerAddress)
+			}
+			if p.KeystorePath != "" || p.KeystorePasswordFile != "" {
+				return nil, fmt.Errorf("%s: set either SIGNER_URL or KEYSTORE_PATH, not both", p.Name)
+			}
+		} else if p.KeystorePath == "" {
+			return nil, fmt.Errorf("%s: missing KEYSTORE_PATH or SIGNER_URL", p.Name)
+		} else if p.SignerAddress != "" {
+			return nil, fmt.Errorf("%s: SIGNER_ADDRESS is only used with SIGNER_URL", p.Name)
+		}
+
+		if p.MaxBidValueUSD != nil {
+			if _, err = parseDecimal(*p.MaxBidValueUSD, decimal.Zero); err != nil {
+				return nil, fmt.Errorf("%s: MAX_BID_VALUE_USD=%q: %s", p.Name, *p.MaxBidValueUSD, err.Error())
+			}
+		}
+	}
+	return
+}
+
+// the default account goes first, followed by ACCOUNTS in order
+func (c *Config) loadAccounts(interactive bool) (err error) {
+	c.Accounts = []*Account{{
+		Name:       DefaultAccount,
+		Address:    c.Address,
+		PrivateKey: c.PrivateKey,
+		SignerUrl:  c.SignerUrl,
+	}}
+	addresses := map[string]string{c.Address: DefaultAccount}
+
+	for _, p := range c.accountParams {
+		account := &Account{Name: p.Name, SignerUrl: p.SignerUrl}
+		if p.MaxBidValueUSD != nil {
+			account.MaxBidValueUSD, _ = decimal.NewFromString(*p.MaxBidValueUSD)
+		}
+
+		if p.SignerUrl != "" {
+			account.Address = strings.ToLower(p.SignerAddress)
+		} else {
+			var password string
+			if p.KeystorePasswordFile != "" {
+				content, readErr := ioutil.ReadFile(p.KeystorePasswordFile)
+				if readErr != nil {
+					return fmt.Errorf("%s: %s", p.Name, readErr.Error())
+				}
+				password = strings.TrimRight(string(content), "\r\n")
+			} else if !interactive {
+				return fmt.Errorf("%s: missing KEYSTORE_PASSWORD_FILE", p.Name)
+			} else if password, err = readPassword(p.KeystorePath); err != nil {
+				return
+			}
+			if account.PrivateKey, err = decryptKeystore(p.KeystorePath, password); err != nil {
+				return
+			}
+			account.Address = utils.PubKey2Address(account.PrivateKey.PublicKey)
+		}
+
+		if name, exist := addresses[account.Address]; exist {
+			return fmt.Errorf("accounts %s and %s have the same address %s", name, p.Name, account.Address)
+		}
+		addresses[account.Address] = p.Name
+		c.Accounts = append(c.Accounts, account)
+	}
+	return
+}
+
+func readPassword(keystorePath string) (password string, err error) {
+	fmt.Printf("Enter password of %s:", keystorePath)
+	input, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("read keystore password failed: %s", err.Error())
+	}
+	return string(input), nil
+}
+
+func decryptKeystore(keystorePath string, password string) (privateKey *ecdsa.PrivateKey, err error) {
+	keyJson, err := ioutil.ReadFile(keystorePath)
+	if err != nil {
+		return
+	}
+	privateKey, err = utils.DecryptKeystore(keyJson, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s failed: %s", keystorePath, err.Error())
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	WEBHOOKSLACK = "slack" // slack compatible incoming webhook, {"text": ...}
+	WEBHOOKJSON  = "json"  // generic http endpoint receiving the alert as a json object
+)
+
+// Webhook receives the alerts, listed in ALERT_WEBHOOKS.
+type Webhook struct {
+	Url    string `json:"URL"`
+	Format string `json:"FORMAT"` // json by default
+}
+
+func parseWebhooks(value string) (webhooks []*Webhook, err error) {
+	decoder := json.NewDecoder(bytes.NewBufferString(value))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&webhooks); err != nil {
+		return
+	}
+	for i, w := range webhooks {
+		if w == nil {
+			return nil, fmt.Errorf("webhook %d must be an object", i)
+		}
+		if err = parseHttpUrl(w.Url); err != nil {
+			return nil, fmt.Errorf("webhook %d: URL: %s", i, err.Error())
+		}
+		if w.Format == "" {
+			w.Format = WEBHOOKJSON
+		}
+		if w.Format != WEBHOOKSLACK && w.Format != WEBHOOKJSON {
+			return nil, fmt.Errorf("webhook %d: FORMAT must be %s or %s", i, WEBHOOKSLACK, WEBHOOKJSON)
+		}
+	}
+	return
+}
@@ -0,0 +1,409 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+const testPrivateKey = "B7A0C9D2786FC4DD080EA5D619D36771AEB0C8C26C290AFD3451B92BA2B7BC2C"
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath := path.Join(dir, "config.json")
+	if err = ioutil.WriteFile(filePath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	filePath := writeConfig(t, `{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05"
+}`)
+	os.Setenv("PROFIT_MARGIN", "0.02")
+	os.Setenv("MAX_SLIPPAGE", "0.03")
+	defer os.Unsetenv("PROFIT_MARGIN")
+	defer os.Unsetenv("MAX_SLIPPAGE")
+
+	c, err := Load([]string{"--config", filePath, "--no-prompt", "--max-slippage", "0.04"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ProfitMargin.String() != "0.02" {
+		t.Errorf("env should override config.json, got PROFIT_MARGIN %s", c.ProfitMargin.String())
+	}
+	if c.MaxSlippage.String() != "0.04" {
+		t.Errorf("flag should override env, got MAX_SLIPPAGE %s", c.MaxSlippage.String())
+	}
+	if c.MinOrderValueUSD.String() != "100" || c.GasPriceTipsGwei() != 0 || c.ChainID != "1" {
+		t.Errorf("unexpected config %+v", c)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	filePath := writeConfig(t, `{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT,,ETH-DAI",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01x",
+  "GAS_PRICE_LEVEL": "fastest",
+  "MAX_SLIPPAGE": "1.5"
+}`)
+
+	_, err := Load([]string{"--config", filePath, "--no-prompt"})
+	if err == nil {
+		t.Fatal("invalid config should be rejected")
+	}
+	for _, name := range []string{"MARKETS", "PROFIT_MARGIN", "GAS_PRICE_LEVEL", "MAX_SLIPPAGE"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error should mention %s: %s", name, err.Error())
+		}
+	}
+}
+
+func TestLoadRejectsZeroMargin(t *testing.T) {
+	content := func(margin, marketParams string) string {
+		return `{
+  "PRIVATE_KEY": "` + testPrivateKey + `",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT,ETH-DAI",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "` + margin + `",
+  "MARKET_PARAMS": ` + marketParams + `,
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05"
+}`
+	}
+
+	filePath := writeConfig(t, content("0", "{}"))
+	if _, err := Load([]string{"--config", filePath, "--no-prompt"}); err == nil || !strings.Contains(err.Error(), "ALLOW_ZERO_MARGIN") {
+		t.Errorf("PROFIT_MARGIN 0 should be rejected, got %v", err)
+	}
+	filePath = writeConfig(t, content("0.01", `{"ETH-DAI": {"PROFIT_MARGIN": "0.0"}}`))
+	if _, err := Load([]string{"--config", filePath, "--no-prompt"}); err == nil || !strings.Contains(err.Error(), "ETH-DAI") {
+		t.Errorf("PROFIT_MARGIN 0 of a market should be rejected, got %v", err)
+	}
+
+	os.Setenv("ALLOW_ZERO_MARGIN", "true")
+	c, err := Load([]string{"--config", filePath, "--no-prompt"})
+	os.Unsetenv("ALLOW_ZERO_MARGIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := c.Market("ETH-DAI"); !c.AllowZeroMargin || !m.ProfitMargin.IsZero() {
+		t.Errorf("PROFIT_MARGIN 0 should be accepted with ALLOW_ZERO_MARGIN %+v", m)
+	}
+	filePath = writeConfig(t, content("0", "{}"))
+	if _, err = Load([]string{"--config", filePath, "--no-prompt", "--allow-zero-margin"}); err != nil {
+		t.Errorf("PROFIT_MARGIN 0 should be accepted with --allow-zero-margin, got %v", err)
+	}
+
+	// a new config.json or an api override can't set it to 0 either
+	ioutil.WriteFile(filePath, []byte(content("0.01", "{}")), 0600)
+	if c, err = Load([]string{"--config", filePath, "--no-prompt"}); err != nil {
+		t.Fatal(err)
+	}
+	zero := "0"
+	if _, err = c.WithMarketParams("ETH-USDT", &MarketParams{ProfitMargin: &zero}); err == nil {
+		t.Error("an override with PROFIT_MARGIN 0 should be rejected")
+	}
+	ioutil.WriteFile(filePath, []byte(content("0", "{}")), 0600)
+	if _, err = c.Reload(); err == nil {
+		t.Error("a new config.json with PROFIT_MARGIN 0 should be rejected")
+	}
+}
+
+func TestLoadNoPromptFailsOnMissing(t *testing.T) {
+	filePath := writeConfig(t, `{"MARKETS": "ETH-USDT"}`)
+
+	_, err := Load([]string{"--config", filePath, "--no-prompt"})
+	if err == nil || !strings.Contains(err.Error(), "PROFIT_MARGIN") {
+		t.Fatalf("missing PROFIT_MARGIN should fail, got %v", err)
+	}
+
+	filePath = writeConfig(t, `{
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05"
+}`)
+	_, err = Load([]string{"--config", filePath, "--no-prompt"})
+	if err == nil || !strings.Contains(err.Error(), "KEYSTORE_PATH") {
+		t.Fatalf("missing key should fail, got %v", err)
+	}
+}
+
+func TestLoadReadOnly(t *testing.T) {
+	filePath := writeConfig(t, `{"SQLITEPATH": "/tmp/ledger", "NETWORK": "ropsten"}`)
+
+	c, err := LoadReadOnly([]string{"--config", filePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SqlitePath != "/tmp/ledger" || c.PrivateKey != nil || len(c.Accounts) != 0 || c.ChainID != "3" {
+		t.Errorf("unexpected config %+v", c)
+	}
+}
+
+func TestLoadKeystore(t *testing.T) {
+	privateKey, _ := crypto.HexToECDSA(testPrivateKey)
+	keyJson, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath := writeConfig(t, `{
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05"
+}`)
+	keystorePath := path.Join(path.Dir(filePath), "keystore.json")
+	ioutil.WriteFile(keystorePath, keyJson, 0600)
+	passwordPath := path.Join(path.Dir(filePath), "password")
+	ioutil.WriteFile(passwordPath, []byte("secret\n"), 0600)
+
+	c, err := Load([]string{"--config", filePath, "--no-prompt", "--keystore", keystorePath, "--keystore-password-file", passwordPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.EqualFold(c.Address, crypto.PubkeyToAddress(privateKey.PublicKey).Hex()) {
+		t.Errorf("unexpected address %s", c.Address)
+	}
+
+	os.Setenv("KEYSTORE_PASSWORD", "wrong")
+	defer os.Unsetenv("KEYSTORE_PASSWORD")
+	if _, err = Load([]string{"--config", filePath, "--no-prompt", "--keystore", keystorePath}); err == nil {
+		t.Error("wrong password should fail")
+	}
+	if os.Getenv("KEYSTORE_PASSWORD") != "" {
+		t.Error("KEYSTORE_PASSWORD should be removed from the environment")
+	}
+}
+
+func TestLoadAccounts(t *testing.T) {
+	privateKey, _ := crypto.HexToECDSA(testPrivateKey)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	keyJson, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.NewRandom(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := path.Dir(writeConfig(t, "{}"))
+	keystorePath := path.Join(dir, "keystore.json")
+	ioutil.WriteFile(keystorePath, keyJson, 0600)
+	passwordPath := path.Join(dir, "password")
+	ioutil.WriteFile(passwordPath, []byte("secret\n"), 0600)
+
+	load := func(accounts string) (*Config, error) {
+		filePath := writeConfig(t, `{
+  "SIGNER_URL": "http://localhost:8550",
+  "SIGNER_ADDRESS": "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05",
+  "ACCOUNTS": `+accounts+`
+}`)
+		return Load([]string{"--config", filePath, "--no-prompt"})
+	}
+
+	c, err := load(`[
+    {"NAME": "hot", "KEYSTORE_PATH": "` + keystorePath + `", "KEYSTORE_PASSWORD_FILE": "` + passwordPath + `", "MAX_BID_VALUE_USD": "5000"},
+    {"NAME": "cold", "SIGNER_URL": "http://localhost:8551", "SIGNER_ADDRESS": "0x06898143DF04616a8A8F9614deb3B99Ba12b3096"}
+  ]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Accounts) != 3 || c.Accounts[0].Name != DefaultAccount || c.Accounts[0].SignerUrl != "http://localhost:8550" {
+		t.Fatalf("unexpected accounts %+v", c.Accounts)
+	}
+	if !strings.EqualFold(c.Accounts[1].Address, address) || c.Accounts[1].MaxBidValueUSD.String() != "5000" {
+		t.Errorf("unexpected account %+v", c.Accounts[1])
+	}
+	if c.Accounts[2].PrivateKey != nil || c.Accounts[2].Address != "0x06898143df04616a8a8f9614deb3b99ba12b3096" {
+		t.Errorf("unexpected account %+v", c.Accounts[2])
+	}
+
+	for _, accounts := range []string{
+		`[{"NAME": "hot", "KEYSTORE_PATH": "` + keystorePath + `"}]`,
+		`[{"NAME": "default", "SIGNER_URL": "http://localhost:8551", "SIGNER_ADDRESS": "0x06898143DF04616a8A8F9614deb3B99Ba12b3096"}]`,
+		`[{"NAME": "same", "SIGNER_URL": "http://localhost:8551", "SIGNER_ADDRESS": "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7"}]`,
+		`[{"NAME": "both", "KEYSTORE_PATH": "` + keystorePath + `", "SIGNER_URL": "http://localhost:8551"}]`,
+		`[{"NAME": "unknown", "PRIVATE_KEY": "` + testPrivateKey + `"}]`,
+	} {
+		if _, err = load(accounts); err == nil {
+			t.Errorf("ACCOUNTS %s should be rejected", accounts)
+		}
+	}
+}
+
+func TestValidateMarkets(t *testing.T) {
+	c := &Config{Markets: []string{"ETH-USDT", "ETH-USDT6"}}
+	if err := c.ValidateMarkets([]string{"ETH-USDT", "ETH-DAI"}); err == nil {
+		t.Error("ETH-USDT6 is not listed and should be rejected")
+	}
+	if err := c.ValidateMarkets([]string{"ETH-USDT", "ETH-USDT6"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReloadAndUpdate(t *testing.T) {
+	filePath := writeConfig(t, `{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05"
+}`)
+	c, err := Load([]string{"--config", filePath, "--no-prompt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(filePath, []byte(`{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8546",
+  "MARKETS": "ETH-USDT,ETH-DAI",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.02",
+  "GAS_PRICE_LEVEL": "flash-boy",
+  "MAX_SLIPPAGE": "0.05"
+}`), 0600)
+	next, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, changes, ignored := c.Update(next)
+	if len(changes) != 3 || len(ignored) != 1 {
+		t.Errorf("unexpected changes %v ignored %v", changes, ignored)
+	}
+	if updated.ProfitMargin.String() != "0.02" || len(updated.Markets) != 2 || updated.GasPriceTipsGwei() != 25 {
+		t.Errorf("reloadable parameters not applied %+v", updated)
+	}
+	if updated.EthereumNodeUrl != "http://localhost:8545" || c.ProfitMargin.String() != "0.01" {
+		t.Errorf("only reloadable parameters of a copy should change")
+	}
+
+	ioutil.WriteFile(filePath, []byte(`{"PROFIT_MARGIN": "0.0.2"}`), 0600)
+	if _, err = c.Reload(); err == nil {
+		t.Error("invalid config should be rejected")
+	}
+}
+
+func TestWithMarketParams(t *testing.T) {
+	filePath := writeConfig(t, `{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT,ETH-DAI",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05",
+  "MARKET_PARAMS": {"ETH-DAI": {"ENABLED": false}}
+}`)
+	c, err := Load([]string{"--config", filePath, "--no-prompt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	margin := "0.02"
+	next, err := c.WithMarketParams("ETH-USDT", &MarketParams{ProfitMargin: &margin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, changes, ignored := c.Update(next)
+	if len(changes) != 1 || len(ignored) != 0 {
+		t.Errorf("unexpected changes %v ignored %v", changes, ignored)
+	}
+	if market, _ := updated.Market("ETH-USDT"); market.ProfitMargin.String() != "0.02" {
+		t.Errorf("market params not applied %+v", market)
+	}
+	if market, _ := updated.Market("ETH-DAI"); market.Enabled {
+		t.Errorf("other markets should keep their params")
+	}
+
+	if next, err = updated.WithMarketParams("ETH-DAI", nil); err != nil {
+		t.Fatal(err)
+	}
+	if market, _ := next.Market("ETH-DAI"); !market.Enabled {
+		t.Errorf("market params not removed")
+	}
+
+	invalid := "2"
+	if _, err = c.WithMarketParams("ETH-USDT", &MarketParams{MaxSlippage: &invalid}); err == nil {
+		t.Error("invalid market params should be rejected")
+	}
+	if _, err = c.WithMarketParams("WBTC-USDT", &MarketParams{}); err == nil {
+		t.Error("market not monitored should be rejected")
+	}
+}
+
+func TestMarketParams(t *testing.T) {
+	filePath := writeConfig(t, `{
+  "PRIVATE_KEY": "`+testPrivateKey+`",
+  "ETHEREUM_NODE_URL": "http://localhost:8545",
+  "MARKETS": "ETH-USDT,WBTC-USDT",
+  "MIN_ORDER_VALUE_USD": "100",
+  "PROFIT_MARGIN": "0.01",
+  "GAS_PRICE_LEVEL": "fast",
+  "MAX_SLIPPAGE": "0.05",
+  "MARKET_PARAMS": {
+    "ETH-USDT": {"PROFIT_MARGIN": "0.005", "GAS_PRICE_LEVEL": "flash-boy"},
+    "WBTC-USDT": {"ENABLED": false, "MAX_BID_VALUE_USD": "1000"}
+  }
+}`)
+	c, err := Load([]string{"--config", filePath, "--no-prompt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	market, ok := c.Market("ETH-USDT")
+	if !ok || !market.Enabled || market.ProfitMargin.String() != "0.005" || market.MaxSlippage.String() != "0.05" || market.GasPriceTipsGwei() != 25 {
+		t.Errorf("unexpected ETH-USDT params %+v", market)
+	}
+	market, ok = c.Market("WBTC-USDT")
+	if !ok || market.Enabled || market.MaxBidValueUSD.String() != "1000" || market.ProfitMargin.String() != "0.01" {
+		t.Errorf("unexpected WBTC-USDT params %+v", market)
+	}
+	if _, ok = c.Market("ETH-USDT6"); ok {
+		t.Error("ETH-USDT6 is not monitored")
+	}
+
+	if _, err = parseMarketParams(`{"ETH-USDT": {"PROFIT_MARGN": "0.005"}}`); err == nil {
+		t.Error("unknown field should be rejected")
+	}
+	if _, err = parseMarketParams(`{"ETH-USDT": {"MAX_SLIPPAGE": "0.05x"}}`); err == nil {
+		t.Error("invalid value should be rejected")
+	}
+}
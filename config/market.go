@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// MarketParams overrides the global parameters for one market. Nil fields fall back to the global value.
+type MarketParams struct {
+	Enabled          *bool   `json:"ENABLED,omitempty"`
+	ProfitMargin     *string `json:"PROFIT_MARGIN,omitempty"`
+	MaxSlippage      *string `json:"MAX_SLIPPAGE,omitempty"`
+	MinOrderValueUSD *string `json:"MIN_ORDER_VALUE_USD,omitempty"`
+	MaxBidValueUSD   *string `json:"MAX_BID_VALUE_USD,omitempty"`
+	GasPriceLevel    *string `json:"GAS_PRICE_LEVEL,omitempty"`
+}
+
+// Market is the effective parameters of a monitored market.
+type Market struct {
+	TradingPair      string
+	Enabled          bool
+	ProfitMargin     decimal.Decimal
+	MaxSlippage      decimal.Decimal
+	MinOrderValueUSD decimal.Decimal
+	MaxBidValueUSD   decimal.Decimal // zero means no limit
+	GasPriceLevel    string
+}
+
+// GasPriceTipsGwei is added to the "fast" gas price from ether gas station.
+func (m *Market) GasPriceTipsGwei() int {
+	return gasPriceTipsGwei[m.GasPriceLevel]
+}
+
+// IsMonitored reports whether the trading pair is one of MARKETS. The match is exact, ETH-USDT doesn't match ETH-USDT6.
+func (c *Config) IsMonitored(tradingPair string) bool {
+	for _, market := range c.Markets {
+		if market == tradingPair {
+			return true
+		}
+	}
+	return false
+}
+
+// Market returns the parameters of a monitored market with its overrides applied.
+func (c *Config) Market(tradingPair string) (market *Market, ok bool) {
+	if !c.IsMonitored(tradingPair) {
+		return nil, false
+	}
+	market = &Market{
+		TradingPair:      tradingPair,
+		Enabled:          true,
+		ProfitMargin:     c.ProfitMargin,
+		MaxSlippage:      c.MaxSlippage,
+		MinOrderValueUSD: c.MinOrderValueUSD,
+		MaxBidValueUSD:   decimal.Zero,
+		GasPriceLevel:    c.GasPriceLevel,
+	}
+	if overrides, exist := c.MarketParams[tradingPair]; exist {
+		// overrides are validated by parseMarketParams
+		overrides.apply(market)
+	}
+	return market, true
+}
+
+func (p *MarketParams) apply(market *Market) (err error) {
+	if p.Enabled != nil {
+		market.Enabled = *p.Enabled
+	}
+	if p.ProfitMargin != nil {
+		if market.ProfitMargin, err = parseDecimal(*p.ProfitMargin, decimal.New(1, 0)); err != nil {
+			return fmt.Errorf("PROFIT_MARGIN=%q: %s", *p.ProfitMargin, err.Error())
+		}
+	}
+	if p.MaxSlippage != nil {
+		if market.MaxSlippage, err = parseDecimal(*p.MaxSlippage, decimal.New(1, 0)); err == nil && market.MaxSlippage.IsZero() {
+			err = fmt.Errorf("must be greater than 0")
+		}
+		if err != nil {
+			return fmt.Errorf("MAX_SLIPPAGE=%q: %s", *p.MaxSlippage, err.Error())
+		}
+	}
+	if p.MinOrderValueUSD != nil {
+		if market.MinOrderValueUSD, err = parseDecimal(*p.MinOrderValueUSD, decimal.Zero); err != nil {
+			return fmt.Errorf("MIN_ORDER_VALUE_USD=%q: %s", *p.MinOrderValueUSD, err.Error())
+		}
+	}
+	if p.MaxBidValueUSD != nil {
+		if market.MaxBidValueUSD, err = parseDecimal(*p.MaxBidValueUSD, decimal.Zero); err != nil {
+			return fmt.Errorf("MAX_BID_VALUE_USD=%q: %s", *p.MaxBidValueUSD, err.Error())
+		}
+	}
+	if p.GasPriceLevel != nil {
+		if _, ok := gasPriceTipsGwei[*p.GasPriceLevel]; !ok {
+			return fmt.Errorf("GAS_PRICE_LEVEL=%q: must be one of fast, super-fast, flash-boy", *p.GasPriceLevel)
+		}
+		market.GasPriceLevel = *p.GasPriceLevel
+	}
+	return
+}
+
+func parseMarketParams(value string) (marketParams map[string]*MarketParams, err error) {
+	decoder := json.NewDecoder(bytes.NewBufferString(value))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&marketParams); err != nil {
+		return
+	}
+	for tradingPair, p := range marketParams {
+		if !marketPattern.MatchString(tradingPair) {
+			return nil, fmt.Errorf("invalid market %q", tradingPair)
+		}
+		if p == nil {
+			return nil, fmt.Errorf("%s: params must be an object", tradingPair)
+		}
+		if err = p.apply(&Market{}); err != nil {
+			return nil, fmt.Errorf("%s: %s", tradingPair, err.Error())
+		}
+	}
+	return
+}
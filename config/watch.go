@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strings"
+	"time"
+)
+
+// Reload reads config.json again on top of the environment and flags captured at startup.
+// Only reloadable parameters are parsed, a missing or invalid one is an error. It never prompts.
+func (c *Config) Reload() (next *Config, err error) {
+	values, err := c.merge()
+	if err != nil {
+		return
+	}
+
+	copied := *c
+	next = &copied
+	next.values = map[string]string{}
+	var invalid []string
+	for _, p := range params {
+		if p.secret {
+			continue
+		}
+		value, ok := values[p.name]
+		if !ok && p.prompt {
+			invalid = append(invalid, fmt.Sprintf("%s is missing", p.name))
+			continue
+		} else if !ok {
+			if p.defaults == nil {
+				continue
+			}
+			value = p.defaults(c.Network)
+		}
+		next.values[p.name] = value
+		if !p.reloadable {
+			continue
+		}
+		if parseErr := p.parse(next, value); parseErr != nil {
+			invalid = append(invalid, fmt.Sprintf("%s=%q: %s", p.name, value, parseErr.Error()))
+		}
+	}
+	if len(invalid) > 0 {
+		err = fmt.Errorf("invalid parameters: %s", strings.Join(invalid, "; "))
+		return
+	}
+	if err = next.checkMargins(); err != nil {
+		return
+	}
+
+	fileValues, _ := readFile(c.Path)
+	for name, value := range fileValues {
+		if value == values[name] {
+			continue
+		}
+		if _, ok := c.flags[name]; ok {
+			logrus.Warnf("%s in %s is overridden by command line flag", name, c.Path)
+		} else if _, ok := c.env[name]; ok {
+			logrus.Warnf("%s in %s is overridden by environment variable", name, c.Path)
+		}
+	}
+	return
+}
+
+// Update returns a copy of c with the reloadable parameters taken from next.
+// changes lists what was applied, ignored lists changes that need a restart.
+func (c *Config) Update(next *Config) (updated *Config, changes []string, ignored []string) {
+	copied := *c
+	updated = &copied
+	updated.values = map[string]string{}
+	for name, value := range c.values {
+		updated.values[name] = value
+	}
+
+	for _, p := range params {
+		oldValue, newValue := c.values[p.name], next.values[p.name]
+		if oldValue == newValue {
+			continue
+		}
+		change := fmt.Sprintf("%s: %s -> %s", p.name, oldValue, newValue)
+		if p.secret {
+			change = fmt.Sprintf("%s changed", p.name)
+		}
+		if !p.reloadable {
+			ignored = append(ignored, change)
+			continue
+		}
+		p.parse(updated, newValue)
+		updated.values[p.name] = newValue
+		changes = append(changes, change)
+	}
+	return
+}
+
+// Watch polls config.json and sends every valid new version of it.
+// Invalid versions are logged and skipped.
+func (c *Config) Watch(interval time.Duration) <-chan *Config {
+	ch := make(chan *Config)
+	go func() {
+		var lastModTime time.Time
+		var lastSize int64
+		if info, err := os.Stat(c.Path); err == nil {
+			lastModTime, lastSize = info.ModTime(), info.Size()
+		}
+		for {
+			time.Sleep(interval)
+			info, err := os.Stat(c.Path)
+			if err != nil || (info.ModTime().Equal(lastModTime) && info.Size() == lastSize) {
+				continue
+			}
+			lastModTime, lastSize = info.ModTime(), info.Size()
+
+			next, err := c.Reload()
+			if err != nil {
+				logrus.Errorf("reject new %s: %s", c.Path, err.Error())
+				continue
+			}
+			ch <- next
+		}
+	}()
+
+	return ch
+}
+
+// WithMarketParams returns a copy of c with the overrides of a monitored market replaced, nil params remove them.
+// Like a new version of config.json, the copy is applied with Update. It is not written to config.json.
+func (c *Config) WithMarketParams(tradingPair string, p *MarketParams) (next *Config, err error) {
+	if !c.IsMonitored(tradingPair) {
+		return nil, fmt.Errorf("%s is not in MARKETS", tradingPair)
+	}
+	if p != nil {
+		if err = p.apply(&Market{}); err != nil {
+			return
+		}
+	}
+	marketParams := map[string]*MarketParams{}
+	for market, overrides := range c.MarketParams {
+		marketParams[market] = overrides
+	}
+	if p == nil {
+		delete(marketParams, tradingPair)
+	} else {
+		marketParams[tradingPair] = p
+	}
+	encoded, err := json.Marshal(marketParams)
+	if err != nil {
+		return
+	}
+
+	copied := *c
+	next = &copied
+	next.values = map[string]string{}
+	for name, value := range c.values {
+		next.values[name] = value
+	}
+	next.values["MARKET_PARAMS"] = string(encoded)
+	next.MarketParams = marketParams
+	if err = next.checkMargins(); err != nil {
+		next = nil
+	}
+	return
+}
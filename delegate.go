@@ -0,0 +1,75 @@
+package main
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/web3"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+const delegateUsage = "usage: main delegate <status|grant|revoke> <delegate address> [flags]"
+
+// Hydro delegates can match orders on behalf of the account. They can't fill auctions
+// with the account balance, fillAuctionWithAmount always settles against msg.sender.
+func runDelegate(args []string) (err error) {
+	if len(args) < 2 {
+		return errors.New(delegateUsage)
+	}
+	action, delegateAddress := args[0], args[1]
+	if action != "status" && action != "grant" && action != "revoke" {
+		return errors.New(delegateUsage)
+	}
+	if !common.IsHexAddress(delegateAddress) {
+		return fmt.Errorf("invalid delegate address %s", delegateAddress)
+	}
+
+	cfg, err := config.Load(args[2:])
+	if err != nil {
+		return
+	}
+	cfg.Export()
+
+	bidderClient, err := client.NewBidderClient(newSigner(cfg.Accounts[0]), nil, nil)
+	if err != nil {
+		return
+	}
+
+	gasPriceInGwei := web3.GetGasPriceGwei() + int64(cfg.GasPriceTipsGwei())
+	return delegate(bidderClient, action, delegateAddress, cfg.Address, gasPriceInGwei)
+}
+
+func delegate(bidderClient *client.BidderClient, action string, delegateAddress string, address string, gasPriceInGwei int64) (err error) {
+	var txHash string
+	switch action {
+	case "status":
+		var isDelegate bool
+		isDelegate, err = bidderClient.IsDelegate(delegateAddress)
+		if err == nil {
+			logrus.Infof("%s is delegate of %s: %t", delegateAddress, address, isDelegate)
+		}
+		return
+	case "grant":
+		txHash, err = bidderClient.ApproveDelegate(delegateAddress, gasPriceInGwei)
+	case "revoke":
+		txHash, err = bidderClient.RevokeDelegate(delegateAddress, gasPriceInGwei)
+	default:
+		return errors.New(delegateUsage)
+	}
+	if err != nil {
+		return
+	}
+
+	logrus.Infof("send tx %s, waiting for receipt", txHash)
+	receipt, err := bidderClient.WaitForReceipt(txHash)
+	if err != nil {
+		return
+	}
+	if receipt.Status == "0x0" {
+		return fmt.Errorf("transaction %s reverted", txHash)
+	}
+	logrus.Infof("%s delegate %s of %s in block %d", action, delegateAddress, address, receipt.BlockNumber)
+	return
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/simulator"
+	"auctionBidder/utils"
+	"auctionBidder/web3"
+	"github.com/shopspring/decimal"
+	"strings"
+	"testing"
+)
+
+func TestRunDelegateUsage(t *testing.T) {
+	delegateAddress := "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7"
+	for _, args := range [][]string{{}, {"grant"}, {"burn", delegateAddress}} {
+		if err := runDelegate(args); err == nil || err.Error() != delegateUsage {
+			t.Errorf("%v: expect the usage, got %v", args, err)
+		}
+	}
+	if err := runDelegate([]string{"grant", "0x1234"}); err == nil || !strings.Contains(err.Error(), "invalid delegate address") {
+		t.Errorf("expect an invalid address, got %v", err)
+	}
+}
+
+func TestDelegate(t *testing.T) {
+	s := simulator.New()
+	s.Activate()
+	defer s.Deactivate()
+	privateKey, _ := utils.NewPrivateKeyByHex("0x3a1076bf45ab87712ad64ccb3b10217737f7faacbf2872e88fdd9a537d8fe266")
+	signer := web3.NewLocalSigner(privateKey)
+	s.SetEthBalance(signer.Address(), decimal.New(1, 0))
+	bidderClient, err := client.NewBidderClient(signer, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delegateAddress := "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7"
+	isDelegate := func() bool {
+		granted, err := bidderClient.IsDelegate(delegateAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return granted
+	}
+	for _, step := range []struct {
+		action   string
+		expected bool
+	}{{"status", false}, {"grant", true}, {"status", true}, {"revoke", false}} {
+		if err = delegate(bidderClient, step.action, delegateAddress, signer.Address(), 10); err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+		if isDelegate() != step.expected {
+			t.Errorf("after %s the delegate should be %t", step.action, step.expected)
+		}
+	}
+}
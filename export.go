@@ -0,0 +1,303 @@
+package main
+
+import (
+	"auctionBidder/config"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportUsage = "usage: main export [--decisions] [--format csv|jsonl] [--from 2019-10-01] [--to 2019-10-31] [--market ETH-USDT,ETH-DAI] [--output file] [-- config flags]"
+
+// one row per bid, the hedge orders of a bid are joined
+type exportRow struct {
+	Time              string `json:"time"`
+	Account           string `json:"account"`
+	Market            string `json:"market"`
+	AuctionID         int64  `json:"auctionId"`
+	TxHash            string `json:"txHash"`
+	Status            string `json:"status"`
+	Error             string `json:"error,omitempty"`
+	BlockNumber       int64  `json:"blockNumber"`
+	DebtSymbol        string `json:"debtSymbol"`
+	CollateralSymbol  string `json:"collateralSymbol"`
+	RepayDebt         string `json:"repayDebt"`
+	ReceiveCollateral string `json:"receiveCollateral"`
+	HedgeOrders       string `json:"hedgeOrders"`
+	HedgeSold         string `json:"hedgeSold"`
+	Proceeds          string `json:"proceeds"`
+	OpenCollateral    string `json:"openCollateral"`
+	GasCostETH        string `json:"gasCostEth"`
+	GasUSD            string `json:"gasUsd"`
+	FeesUSD           string `json:"feesUsd"`
+	GrossUSD          string `json:"grossUsd"`
+	RealizedUSD       string `json:"realizedUsd"`
+	Priced            bool   `json:"priced"`
+}
+
+var exportColumns = []string{
+	"time", "account", "market", "auctionId", "txHash", "status", "error", "blockNumber",
+	"debtSymbol", "collateralSymbol", "repayDebt", "receiveCollateral",
+	"hedgeOrders", "hedgeSold", "proceeds", "openCollateral",
+	"gasCostEth", "gasUsd", "feesUsd", "grossUsd", "realizedUsd", "priced",
+}
+
+func (r *exportRow) csv() []string {
+	return []string{
+		r.Time, r.Account, r.Market, strconv.FormatInt(r.AuctionID, 10), r.TxHash, r.Status, r.Error, strconv.FormatInt(r.BlockNumber, 10),
+		r.DebtSymbol, r.CollateralSymbol, r.RepayDebt, r.ReceiveCollateral,
+		r.HedgeOrders, r.HedgeSold, r.Proceeds, r.OpenCollateral,
+		r.GasCostETH, r.GasUSD, r.FeesUSD, r.GrossUSD, r.RealizedUSD, strconv.FormatBool(r.Priced),
+	}
+}
+
+func newExportRow(trade *storage.Trade) *exportRow {
+	bid := trade.Bid
+	row := &exportRow{
+		Account:          bid.Account,
+		Market:           bid.Market,
+		AuctionID:        bid.AuctionID,
+		TxHash:           bid.TxHash,
+		Status:           bid.Status,
+		Error:            bid.Error,
+		DebtSymbol:       bid.DebtSymbol,
+		CollateralSymbol: bid.CollateralSymbol,
+		RepayDebt:        bid.RepayDebt.String(),
+	}
+	if bid.CreatedAt > 0 {
+		row.Time = time.Unix(0, bid.CreatedAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	}
+
+	computed := pnl.ComputeTrade(trade)
+	if computed == nil {
+		return row
+	}
+	// the repaid and received amounts of a mined bid come from the transaction
+	row.BlockNumber = trade.Fill.BlockNumber
+	row.RepayDebt = trade.Fill.RepayDebt.String()
+	row.ReceiveCollateral = trade.Fill.ReceiveCollateral.String()
+	row.GasCostETH = trade.Fill.GasCost.String()
+
+	var orderIds []string
+	sold, proceeds := decimal.Zero, decimal.Zero
+	for _, order := range trade.HedgeOrders {
+		orderIds = append(orderIds, order.DdexOrderId)
+		sold = sold.Add(order.SellAmount)
+		proceeds = proceeds.Add(order.ReceiveAmount)
+	}
+	row.HedgeOrders = strings.Join(orderIds, ";")
+	row.HedgeSold = sold.String()
+	row.Proceeds = proceeds.String()
+	row.OpenCollateral = computed.OpenAmount.String()
+	row.GasUSD = computed.GasUSD.StringFixed(2)
+	row.FeesUSD = computed.FeesUSD.StringFixed(2)
+	row.GrossUSD = computed.GrossUSD.StringFixed(2)
+	row.RealizedUSD = computed.RealizedUSD.StringFixed(2)
+	row.Priced = computed.Priced
+	return row
+}
+
+func writeExport(w io.Writer, format string, trades []*storage.Trade) (err error) {
+	if format == "jsonl" {
+		encoder := json.NewEncoder(w)
+		for _, trade := range trades {
+			if err = encoder.Encode(newExportRow(trade)); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write(exportColumns); err != nil {
+		return
+	}
+	for _, trade := range trades {
+		if err = writer.Write(newExportRow(trade).csv()); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// one row per auction per block the bot looked at, with the inputs of the decision
+type decisionRow struct {
+	Time                string `json:"time"`
+	BlockNumber         int64  `json:"blockNumber"`
+	AuctionID           int64  `json:"auctionId"`
+	Market              string `json:"market"`
+	Action              string `json:"action"`
+	Reason              string `json:"reason"`
+	Error               string `json:"error,omitempty"`
+	DebtSymbol          string `json:"debtSymbol"`
+	CollateralSymbol    string `json:"collateralSymbol"`
+	AuctionPrice        string `json:"auctionPrice"`
+	Ratio               string `json:"ratio"`
+	AvailableDebt       string `json:"availableDebt"`
+	AvailableCollateral string `json:"availableCollateral"`
+	FreeDebt            string `json:"freeDebt"`
+	CollateralPriceUSD  string `json:"collateralPriceUsd"`
+	RepayDebt           string `json:"repayDebt"`
+	Collateral          string `json:"collateral"`
+	ExpectedReceiveDebt string `json:"expectedReceiveDebt"`
+	MinReceiveDebt      string `json:"minReceiveDebt"`
+	ProfitMargin        string `json:"profitMargin"`
+	GasPriceGwei        int64  `json:"gasPriceGwei"`
+	ConfigSnapshotID    int64  `json:"configSnapshotId"`
+}
+
+var decisionColumns = []string{
+	"time", "blockNumber", "auctionId", "market", "action", "reason", "error",
+	"debtSymbol", "collateralSymbol", "auctionPrice", "ratio", "availableDebt", "availableCollateral",
+	"freeDebt", "collateralPriceUsd", "repayDebt", "collateral", "expectedReceiveDebt", "minReceiveDebt",
+	"profitMargin", "gasPriceGwei", "configSnapshotId",
+}
+
+func (r *decisionRow) csv() []string {
+	return []string{
+		r.Time, strconv.FormatInt(r.BlockNumber, 10), strconv.FormatInt(r.AuctionID, 10), r.Market, r.Action, r.Reason, r.Error,
+		r.DebtSymbol, r.CollateralSymbol, r.AuctionPrice, r.Ratio, r.AvailableDebt, r.AvailableCollateral,
+		r.FreeDebt, r.CollateralPriceUSD, r.RepayDebt, r.Collateral, r.ExpectedReceiveDebt, r.MinReceiveDebt,
+		r.ProfitMargin, strconv.FormatInt(r.GasPriceGwei, 10), strconv.FormatInt(r.ConfigSnapshotID, 10),
+	}
+}
+
+func newDecisionRow(d *storage.Decision) *decisionRow {
+	return &decisionRow{
+		Time:                time.Unix(0, d.CreatedAt*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+		BlockNumber:         d.BlockNumber,
+		AuctionID:           d.AuctionID,
+		Market:              d.Market,
+		Action:              d.Action,
+		Reason:              d.Reason,
+		Error:               d.Error,
+		DebtSymbol:          d.DebtSymbol,
+		CollateralSymbol:    d.CollateralSymbol,
+		AuctionPrice:        d.AuctionPrice.String(),
+		Ratio:               d.Ratio.String(),
+		AvailableDebt:       d.AvailableDebt.String(),
+		AvailableCollateral: d.AvailableCollateral.String(),
+		FreeDebt:            d.FreeDebt.String(),
+		CollateralPriceUSD:  d.CollateralPriceUSD.String(),
+		RepayDebt:           d.RepayDebt.String(),
+		Collateral:          d.Collateral.String(),
+		ExpectedReceiveDebt: d.ExpectedReceiveDebt.String(),
+		MinReceiveDebt:      d.MinReceiveDebt.String(),
+		ProfitMargin:        d.ProfitMargin.String(),
+		GasPriceGwei:        d.GasPriceGwei,
+		ConfigSnapshotID:    d.ConfigSnapshotID,
+	}
+}
+
+func writeDecisionExport(w io.Writer, format string, decisions []*storage.Decision) (err error) {
+	if format == "jsonl" {
+		encoder := json.NewEncoder(w)
+		for _, decision := range decisions {
+			if err = encoder.Encode(newDecisionRow(decision)); err != nil {
+				return
+			}
+		}
+		return
+	}
+	writer := csv.NewWriter(w)
+	if err = writer.Write(decisionColumns); err != nil {
+		return
+	}
+	for _, decision := range decisions {
+		if err = writer.Write(newDecisionRow(decision).csv()); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// a date covers the whole day, UTC
+func parseExportTime(value string, endOfDay bool) (t time.Time, err error) {
+	if value == "" {
+		return
+	}
+	if t, err = time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// dump the ledger for accounting, one row per bid, or the decisions of the bot
+func runExport(args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	decisions := fs.Bool("decisions", false, "export the decision of every auction in every block instead of the bids")
+	format := fs.String("format", "csv", "csv or jsonl")
+	from := fs.String("from", "", "first day or RFC3339 time of the bids, inclusive")
+	to := fs.String("to", "", "last day, inclusive, or RFC3339 time of the bids, exclusive")
+	markets := fs.String("market", "", "markets separated by commas, all markets if empty")
+	output := fs.String("output", "", "file to write, stdout if empty")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if *format != "csv" && *format != "jsonl" {
+		return errors.New(exportUsage)
+	}
+
+	var filter storage.Filter
+	if filter.From, err = parseExportTime(*from, false); err != nil {
+		return fmt.Errorf("invalid --from %q: %s", *from, err.Error())
+	}
+	if filter.To, err = parseExportTime(*to, true); err != nil {
+		return fmt.Errorf("invalid --to %q: %s", *to, err.Error())
+	}
+	for _, market := range strings.Split(*markets, ",") {
+		if market = strings.TrimSpace(market); market != "" {
+			filter.Markets = append(filter.Markets, strings.ToUpper(market))
+		}
+	}
+
+	cfg, err := config.LoadReadOnly(fs.Args())
+	if err != nil {
+		return
+	}
+	store, err := newStore(cfg)
+	if err != nil {
+		return
+	}
+	defer store.Close()
+
+	var trades []*storage.Trade
+	var records []*storage.Decision
+	if *decisions {
+		records, err = store.QueryDecisions(filter)
+	} else {
+		trades, err = store.QueryTrades(filter)
+	}
+	if err != nil {
+		return
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		var file *os.File
+		if file, err = os.Create(*output); err != nil {
+			return
+		}
+		defer file.Close()
+		w = file
+	}
+	if *decisions {
+		return writeDecisionExport(w, *format, records)
+	}
+	return writeExport(w, *format, trades)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"auctionBidder/storage"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteExport(t *testing.T) {
+	trades := []*storage.Trade{
+		{
+			Bid: &storage.Bid{AuctionID: 7, CreatedAt: 1571220000000, Market: "ETH-USDT", DebtSymbol: "USDT", CollateralSymbol: "ETH",
+				RepayDebt: decimal.New(1000, 0), DebtPriceUSD: decimal.New(1, 0), EthPriceUSD: decimal.New(180, 0), TxHash: "0x07", Status: storage.BIDFILLED},
+			Fill: &storage.Fill{BlockNumber: 100, RepayDebt: decimal.New(1000, 0), ReceiveCollateral: decimal.New(6, 0), GasCost: decimal.New(1, -2)},
+			HedgeOrders: []*storage.HedgeOrder{
+				{DdexOrderId: "0xa", SellAmount: decimal.New(4, 0), ReceiveAmount: decimal.New(720, 0)},
+				{DdexOrderId: "0xb", SellAmount: decimal.New(2, 0), ReceiveAmount: decimal.New(360, 0)},
+			},
+		},
+		{Bid: &storage.Bid{AuctionID: 8, Market: "ETH-DAI", RepayDebt: decimal.New(5, 0), Status: storage.BIDFAILED, Error: "nonce too low"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeExport(&buf, "csv", trades); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(records[1]) != len(exportColumns) {
+		t.Fatalf("unexpected csv %v", records)
+	}
+	row := map[string]string{}
+	for i, column := range exportColumns {
+		row[column] = records[1][i]
+	}
+	expect := map[string]string{
+		"time": time.Unix(1571220000, 0).UTC().Format(time.RFC3339), "txHash": "0x07", "blockNumber": "100",
+		"hedgeOrders": "0xa;0xb", "hedgeSold": "6", "proceeds": "1080", "gasUsd": "1.80", "realizedUsd": "78.20",
+	}
+	for column, value := range expect {
+		if row[column] != value {
+			t.Errorf("%s is %q, expect %q", column, row[column], value)
+		}
+	}
+	if records[2][5] != storage.BIDFAILED || records[2][6] != "nonce too low" || records[2][0] != "" {
+		t.Errorf("unexpected failed bid %v", records[2])
+	}
+
+	buf.Reset()
+	if err = writeExport(&buf, "jsonl", trades); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var parsed exportRow
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &parsed) != nil || parsed.AuctionID != 8 || parsed.Market != "ETH-DAI" {
+		t.Errorf("unexpected jsonl %s", buf.String())
+	}
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"auctionBidder/client"
+	"github.com/sirupsen/logrus"
+)
+
+// Fetch reads the auctions from fromBlock to toBlock from the chain and writes a block for each block they
+// changed in. It reads every step blocks, and every block an auction was filled in with the block before, so
+// the auctions are known before and after the fills of other bidders. Blocks without auctions are only written
+// after a block with auctions. Old blocks need an archive node.
+func Fetch(bidder *client.BidderClient, fromBlock int64, toBlock int64, step int64, w *Writer) (count int, err error) {
+	fills, err := bidder.GetFillBlocks(fromBlock, toBlock)
+	if err != nil {
+		return
+	}
+	numbers := map[int64]bool{}
+	for _, number := range fills {
+		numbers[number-1], numbers[number] = true, true
+	}
+
+	hadAuctions := false
+	for number := fromBlock; number <= toBlock; number++ {
+		if (number-fromBlock)%step != 0 && !numbers[number] {
+			continue
+		}
+		var auctions []*client.Auction
+		if auctions, err = bidder.GetAllAuctionsAt(number); err != nil {
+			return
+		}
+		if len(auctions) == 0 && !hadAuctions {
+			continue
+		}
+		hadAuctions = len(auctions) > 0
+
+		block := &Block{Number: number, Auctions: auctions}
+		if block.Time, block.GasPriceGwei, err = bidder.GetBlockGasPrice(number); err != nil {
+			return
+		}
+		if err = w.Write(block); err != nil {
+			return
+		}
+		count++
+		if count%100 == 0 {
+			logrus.Infof("fetched %d blocks with auctions, at block %d of %d", count, number, toBlock)
+		}
+	}
+	return
+}
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"auctionBidder/client"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Block is the market at a block: the auctions of the hydro contract, the oracle usd prices and the level 2
+// orderbooks of ddex. Blocks fetched from the chain afterwards only have auctions, the backtester carries the
+// prices and orderbooks of the latest block recorded before.
+type Block struct {
+	Number       int64                        `json:"number"`
+	Time         int64                        `json:"time,omitempty"`         // unix seconds
+	GasPriceGwei int64                        `json:"gasPriceGwei,omitempty"` // zero if unknown
+	Auctions     []*client.Auction            `json:"auctions"`
+	Prices       map[string]decimal.Decimal   `json:"prices,omitempty"`
+	Orderbooks   map[string]*client.Orderbook `json:"orderbooks,omitempty"` // trading pair -> orderbook
+}
+
+// merge the fields of the same block recorded in another file
+func (b *Block) merge(other *Block) {
+	if b.Time == 0 {
+		b.Time = other.Time
+	}
+	if b.GasPriceGwei == 0 {
+		b.GasPriceGwei = other.GasPriceGwei
+	}
+	if b.Auctions == nil {
+		b.Auctions = other.Auctions
+	}
+	if b.Prices == nil {
+		b.Prices = other.Prices
+	}
+	if b.Orderbooks == nil {
+		b.Orderbooks = other.Orderbooks
+	}
+}
+
+// Writer appends blocks to a json lines file, gzipped if the path ends with .gz.
+type Writer struct {
+	file    *os.File
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+}
+
+func NewWriter(path string) (w *Writer, err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	w = &Writer{file: file}
+	if strings.HasSuffix(path, ".gz") {
+		// every writer adds a gzip member, readers read them all
+		w.gzip = gzip.NewWriter(file)
+		w.encoder = json.NewEncoder(w.gzip)
+	} else {
+		w.encoder = json.NewEncoder(file)
+	}
+	return
+}
+
+// Write flushes the block to the file, so a crash loses at most the block being written
+func (w *Writer) Write(block *Block) (err error) {
+	if err = w.encoder.Encode(block); err == nil && w.gzip != nil {
+		err = w.gzip.Flush()
+	}
+	return
+}
+
+func (w *Writer) Close() (err error) {
+	if w.gzip != nil {
+		err = w.gzip.Close()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return
+}
+
+// Read loads the blocks of all files, merging those recorded in several of them, by block number.
+func Read(paths ...string) (blocks []*Block, err error) {
+	byNumber := map[int64]*Block{}
+	for _, path := range paths {
+		if err = readFile(path, func(block *Block) {
+			if b, ok := byNumber[block.Number]; ok {
+				b.merge(block)
+			} else {
+				byNumber[block.Number] = block
+				blocks = append(blocks, block)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Number < blocks[j].Number })
+	return
+}
+
+func readFile(path string, add func(block *Block)) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(file); err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; ; line++ {
+		var block Block
+		if err = decoder.Decode(&block); err == io.EOF {
+			return nil
+		} else if err == io.ErrUnexpectedEOF {
+			// the last block of a file not closed, the recorder crashed or is still writing it
+			logrus.Warnf("%s: block %d is truncated, skipped", path, line)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: block %d: %s", path, line, err.Error())
+		}
+		add(&block)
+	}
+}
@@ -0,0 +1,87 @@
+package history
+
+import (
+	"auctionBidder/client"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	recorded, fetched := filepath.Join(dir, "recorded.jsonl.gz"), filepath.Join(dir, "fetched.jsonl")
+
+	// two writers append two gzip members to the same file
+	for _, block := range []*Block{
+		{Number: 2, Prices: map[string]decimal.Decimal{"ETH": decimal.New(200, 0)}, Auctions: []*client.Auction{}},
+		{Number: 1, Orderbooks: map[string]*client.Orderbook{"ETH-USDT": {}}},
+	} {
+		w, err := NewWriter(recorded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Write(block); err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := NewWriter(fetched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(&Block{Number: 1, Time: 1571443200, Auctions: []*client.Auction{{ID: 7}}})
+	w.Close()
+
+	blocks, err := Read(recorded, fetched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].Number != 1 || blocks[1].Number != 2 {
+		t.Fatalf("blocks not sorted %+v", blocks)
+	}
+	if blocks[0].Time != 1571443200 || len(blocks[0].Auctions) != 1 || blocks[0].Orderbooks["ETH-USDT"] == nil {
+		t.Errorf("block 1 not merged %+v", blocks[0])
+	}
+	if blocks[1].Auctions == nil || len(blocks[1].Auctions) != 0 || !blocks[1].Prices["ETH"].Equal(decimal.New(200, 0)) {
+		t.Errorf("a block without auctions must stay distinct from an unknown one %+v", blocks[1])
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := NewRecorder(filepath.Join(dir, "blocks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, time := range []int64{1571443200, 1571443215, 1571529600} {
+		if err = r.Record(&Block{Number: int64(i + 1), Time: time, Auctions: []*client.Auction{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the file of the last day is not closed yet, its blocks are flushed
+	files, _ := filepath.Glob(filepath.Join(dir, "blocks", "*"))
+	if len(files) != 2 || filepath.Base(files[0]) != "blocks-2019-10-19.jsonl.gz" {
+		t.Fatalf("unexpected files %v", files)
+	}
+	blocks, err := Read(files...)
+	if err != nil || len(blocks) != 3 {
+		t.Errorf("unexpected blocks %v %v", blocks, err)
+	}
+	if err = r.Close(); err != nil {
+		t.Error(err)
+	}
+}
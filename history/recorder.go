@@ -0,0 +1,51 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Recorder writes the blocks to a gzipped file per day (UTC) in a directory, blocks-2019-10-19.jsonl.gz.
+type Recorder struct {
+	dir    string
+	mu     sync.Mutex
+	day    string
+	writer *Writer
+}
+
+func NewRecorder(dir string) (r *Recorder, err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	return &Recorder{dir: dir}, nil
+}
+
+// Record writes the block to the file of its day, it is safe to call from several goroutines.
+func (r *Recorder) Record(block *Block) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	day := time.Unix(block.Time, 0).UTC().Format("2006-01-02")
+	if day != r.day {
+		if r.writer != nil {
+			r.writer.Close()
+		}
+		if r.writer, err = NewWriter(filepath.Join(r.dir, "blocks-"+day+".jsonl.gz")); err != nil {
+			r.day = ""
+			return
+		}
+		r.day = day
+	}
+	return r.writer.Write(block)
+}
+
+func (r *Recorder) Close() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.writer != nil {
+		err = r.writer.Close()
+		r.writer, r.day = nil, ""
+	}
+	return
+}
@@ -0,0 +1,5 @@
+// Package hydro has the bindings of the Hydro contract, generated from utils.HydroAbi by web3/bindgen.
+// Run go generate after changing the abi.
+package hydro
+
+//go:generate go run gen.go
@@ -0,0 +1,28 @@
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"auctionBidder/utils"
+	"auctionBidder/web3/bindgen"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+)
+
+func main() {
+	code, err := bindgen.Generate(bindgen.Config{
+		Package:   "hydro",
+		Type:      "Hydro",
+		Abi:       utils.HydroAbi,
+		AbiConst:  "utils.HydroAbi",
+		AbiImport: "auctionBidder/utils",
+		Source:    "web3/bindgen from utils.HydroAbi",
+	})
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if err = ioutil.WriteFile("hydro.go", code, 0644); err != nil {
+		logrus.Fatal(err)
+	}
+}
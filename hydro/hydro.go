@@ -0,0 +1,568 @@
+// Code generated by web3/bindgen from utils.HydroAbi. DO NOT EDIT.
+
+package hydro
+
+import (
+	"auctionBidder/utils"
+	"auctionBidder/web3"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// AccountDetails is a (bool,uint8,uint256,uint256) tuple.
+type AccountDetails struct {
+	Liquidatable          bool
+	Status                uint8
+	DebtsTotalUSDValue    *big.Int
+	BalancesTotalUSDValue *big.Int
+}
+
+// Action is a (uint8,bytes) tuple.
+type Action struct {
+	ActionType    uint8
+	EncodedParams []byte
+}
+
+// Asset is a (address,address,address) tuple.
+type Asset struct {
+	LendingPoolToken common.Address
+	PriceOracle      common.Address
+	InterestModel    common.Address
+}
+
+// AuctionDetails is a (address,uint16,address,address,uint256,uint256,uint256,uint256,bool) tuple.
+type AuctionDetails struct {
+	Borrower             common.Address
+	MarketID             uint16
+	DebtAsset            common.Address
+	CollateralAsset      common.Address
+	LeftDebtAmount       *big.Int
+	LeftCollateralAmount *big.Int
+	Ratio                *big.Int
+	Price                *big.Int
+	Finished             bool
+}
+
+// MakerOrderParam is a (address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32)) tuple.
+type MakerOrderParam struct {
+	Trader           common.Address
+	BaseAssetAmount  *big.Int
+	QuoteAssetAmount *big.Int
+	GasTokenAmount   *big.Int
+	Data             [32]byte
+	Signature        Signature
+}
+
+// Market is a (address,address,uint256,uint256,uint256,uint256,bool) tuple.
+type Market struct {
+	BaseAsset            common.Address
+	QuoteAsset           common.Address
+	LiquidateRate        *big.Int
+	WithdrawRate         *big.Int
+	AuctionRatioStart    *big.Int
+	AuctionRatioPerBlock *big.Int
+	BorrowEnable         bool
+}
+
+// Order is a (address,address,address,address,uint256,uint256,uint256,bytes32) tuple.
+type Order struct {
+	Trader           common.Address
+	Relayer          common.Address
+	BaseAsset        common.Address
+	QuoteAsset       common.Address
+	BaseAssetAmount  *big.Int
+	QuoteAssetAmount *big.Int
+	GasTokenAmount   *big.Int
+	Data             [32]byte
+}
+
+// OrderAddressSet is a (address,address,address) tuple.
+type OrderAddressSet struct {
+	BaseAsset  common.Address
+	QuoteAsset common.Address
+	Relayer    common.Address
+}
+
+// Params is a ((address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32)),(address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32))[],uint256[],(address,address,address)) tuple.
+type Params struct {
+	TakerOrderParam        TakerOrderParam
+	MakerOrderParams       []MakerOrderParam
+	BaseAssetFilledAmounts []*big.Int
+	OrderAddressSet        OrderAddressSet
+}
+
+// Signature is a (bytes32,bytes32,bytes32) tuple.
+type Signature struct {
+	Config [32]byte
+	R      [32]byte
+	S      [32]byte
+}
+
+// TakerOrderParam is a (address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32)) tuple.
+type TakerOrderParam struct {
+	Trader           common.Address
+	BaseAssetAmount  *big.Int
+	QuoteAssetAmount *big.Int
+	GasTokenAmount   *big.Int
+	Data             [32]byte
+	Signature        Signature
+}
+
+// Hydro is the contract at an address, its methods call it through the web3 node.
+type Hydro struct {
+	*web3.Contract
+}
+
+func NewHydro(w *web3.Web3, address string) (contract *Hydro, err error) {
+	c, err := w.NewContract(utils.HydroAbi, address)
+	if err != nil {
+		return
+	}
+	return &Hydro{c}, nil
+}
+
+// ApproveDelegate signs a transaction calling approveDelegate(address), it is not sent.
+func (h *Hydro) ApproveDelegate(tx *web3.SendTxParams, delegate common.Address) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "approveDelegate", delegate)
+}
+
+// BalanceOf calls balanceOf(address,address).
+func (h *Hydro) BalanceOf(opts *web3.CallOpts, asset common.Address, user common.Address) (balance *big.Int, err error) {
+	err = h.Query(opts, &balance, "balanceOf", asset, user)
+	return
+}
+
+// BalanceOfCall is BalanceOf for BatchQuery, balance is set once the batch is sent.
+func (h *Hydro) BalanceOfCall(opts *web3.CallOpts, balance **big.Int, asset common.Address, user common.Address) *web3.ContractCall {
+	return h.NewCall(opts, balance, "balanceOf", asset, user)
+}
+
+// Batch signs a transaction calling batch((uint8,bytes)[]), it is not sent.
+func (h *Hydro) Batch(tx *web3.SendTxParams, value *big.Int, actions []Action) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, value, "batch", actions)
+}
+
+// CanMatchOrdersFrom calls canMatchOrdersFrom(address).
+func (h *Hydro) CanMatchOrdersFrom(opts *web3.CallOpts, relayer common.Address) (canMatch bool, err error) {
+	err = h.Query(opts, &canMatch, "canMatchOrdersFrom", relayer)
+	return
+}
+
+// CanMatchOrdersFromCall is CanMatchOrdersFrom for BatchQuery, canMatch is set once the batch is sent.
+func (h *Hydro) CanMatchOrdersFromCall(opts *web3.CallOpts, canMatch *bool, relayer common.Address) *web3.ContractCall {
+	return h.NewCall(opts, canMatch, "canMatchOrdersFrom", relayer)
+}
+
+// CancelOrder signs a transaction calling cancelOrder((address,address,address,address,uint256,uint256,uint256,bytes32)), it is not sent.
+func (h *Hydro) CancelOrder(tx *web3.SendTxParams, order Order) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "cancelOrder", order)
+}
+
+// CreateAsset signs a transaction calling createAsset(address,address,address,string,string,uint8), it is not sent.
+func (h *Hydro) CreateAsset(tx *web3.SendTxParams, asset common.Address, oracleAddress common.Address, interestModelAddress common.Address, poolTokenName string, poolTokenSymbol string, poolTokenDecimals uint8) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "createAsset", asset, oracleAddress, interestModelAddress, poolTokenName, poolTokenSymbol, poolTokenDecimals)
+}
+
+// CreateMarket signs a transaction calling createMarket((address,address,uint256,uint256,uint256,uint256,bool)), it is not sent.
+func (h *Hydro) CreateMarket(tx *web3.SendTxParams, market Market) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "createMarket", market)
+}
+
+// ExitIncentiveSystem signs a transaction calling exitIncentiveSystem(), it is not sent.
+func (h *Hydro) ExitIncentiveSystem(tx *web3.SendTxParams) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "exitIncentiveSystem")
+}
+
+// FillAuctionWithAmount signs a transaction calling fillAuctionWithAmount(uint32,uint256), it is not sent.
+func (h *Hydro) FillAuctionWithAmount(tx *web3.SendTxParams, auctionID uint32, amount *big.Int) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "fillAuctionWithAmount", auctionID, amount)
+}
+
+// GetAccountDetails calls getAccountDetails(address,uint16).
+func (h *Hydro) GetAccountDetails(opts *web3.CallOpts, user common.Address, marketID uint16) (details AccountDetails, err error) {
+	err = h.Query(opts, &details, "getAccountDetails", user, marketID)
+	return
+}
+
+// GetAccountDetailsCall is GetAccountDetails for BatchQuery, details is set once the batch is sent.
+func (h *Hydro) GetAccountDetailsCall(opts *web3.CallOpts, details *AccountDetails, user common.Address, marketID uint16) *web3.ContractCall {
+	return h.NewCall(opts, details, "getAccountDetails", user, marketID)
+}
+
+// GetAllMarketsCount calls getAllMarketsCount().
+func (h *Hydro) GetAllMarketsCount(opts *web3.CallOpts) (count *big.Int, err error) {
+	err = h.Query(opts, &count, "getAllMarketsCount")
+	return
+}
+
+// GetAllMarketsCountCall is GetAllMarketsCount for BatchQuery, count is set once the batch is sent.
+func (h *Hydro) GetAllMarketsCountCall(opts *web3.CallOpts, count **big.Int) *web3.ContractCall {
+	return h.NewCall(opts, count, "getAllMarketsCount")
+}
+
+// GetAmountBorrowed calls getAmountBorrowed(address,address,uint16).
+func (h *Hydro) GetAmountBorrowed(opts *web3.CallOpts, asset common.Address, user common.Address, marketID uint16) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getAmountBorrowed", asset, user, marketID)
+	return
+}
+
+// GetAmountBorrowedCall is GetAmountBorrowed for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetAmountBorrowedCall(opts *web3.CallOpts, amount **big.Int, asset common.Address, user common.Address, marketID uint16) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getAmountBorrowed", asset, user, marketID)
+}
+
+// GetAmountSupplied calls getAmountSupplied(address,address).
+func (h *Hydro) GetAmountSupplied(opts *web3.CallOpts, asset common.Address, user common.Address) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getAmountSupplied", asset, user)
+	return
+}
+
+// GetAmountSuppliedCall is GetAmountSupplied for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetAmountSuppliedCall(opts *web3.CallOpts, amount **big.Int, asset common.Address, user common.Address) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getAmountSupplied", asset, user)
+}
+
+// GetAsset calls getAsset(address).
+func (h *Hydro) GetAsset(opts *web3.CallOpts, assetAddress common.Address) (asset Asset, err error) {
+	err = h.Query(opts, &asset, "getAsset", assetAddress)
+	return
+}
+
+// GetAssetCall is GetAsset for BatchQuery, asset is set once the batch is sent.
+func (h *Hydro) GetAssetCall(opts *web3.CallOpts, asset *Asset, assetAddress common.Address) *web3.ContractCall {
+	return h.NewCall(opts, asset, "getAsset", assetAddress)
+}
+
+// GetAssetOraclePrice calls getAssetOraclePrice(address).
+func (h *Hydro) GetAssetOraclePrice(opts *web3.CallOpts, assetAddress common.Address) (price *big.Int, err error) {
+	err = h.Query(opts, &price, "getAssetOraclePrice", assetAddress)
+	return
+}
+
+// GetAssetOraclePriceCall is GetAssetOraclePrice for BatchQuery, price is set once the batch is sent.
+func (h *Hydro) GetAssetOraclePriceCall(opts *web3.CallOpts, price **big.Int, assetAddress common.Address) *web3.ContractCall {
+	return h.NewCall(opts, price, "getAssetOraclePrice", assetAddress)
+}
+
+// GetAuctionDetails calls getAuctionDetails(uint32).
+func (h *Hydro) GetAuctionDetails(opts *web3.CallOpts, auctionID uint32) (details AuctionDetails, err error) {
+	err = h.Query(opts, &details, "getAuctionDetails", auctionID)
+	return
+}
+
+// GetAuctionDetailsCall is GetAuctionDetails for BatchQuery, details is set once the batch is sent.
+func (h *Hydro) GetAuctionDetailsCall(opts *web3.CallOpts, details *AuctionDetails, auctionID uint32) *web3.ContractCall {
+	return h.NewCall(opts, details, "getAuctionDetails", auctionID)
+}
+
+// GetAuctionsCount calls getAuctionsCount().
+func (h *Hydro) GetAuctionsCount(opts *web3.CallOpts) (count uint32, err error) {
+	err = h.Query(opts, &count, "getAuctionsCount")
+	return
+}
+
+// GetAuctionsCountCall is GetAuctionsCount for BatchQuery, count is set once the batch is sent.
+func (h *Hydro) GetAuctionsCountCall(opts *web3.CallOpts, count *uint32) *web3.ContractCall {
+	return h.NewCall(opts, count, "getAuctionsCount")
+}
+
+// GetCurrentAuctions calls getCurrentAuctions().
+func (h *Hydro) GetCurrentAuctions(opts *web3.CallOpts) (out []uint32, err error) {
+	err = h.Query(opts, &out, "getCurrentAuctions")
+	return
+}
+
+// GetCurrentAuctionsCall is GetCurrentAuctions for BatchQuery, out is set once the batch is sent.
+func (h *Hydro) GetCurrentAuctionsCall(opts *web3.CallOpts, out *[]uint32) *web3.ContractCall {
+	return h.NewCall(opts, out, "getCurrentAuctions")
+}
+
+// GetDiscountedRate calls getDiscountedRate(address).
+func (h *Hydro) GetDiscountedRate(opts *web3.CallOpts, user common.Address) (rate *big.Int, err error) {
+	err = h.Query(opts, &rate, "getDiscountedRate", user)
+	return
+}
+
+// GetDiscountedRateCall is GetDiscountedRate for BatchQuery, rate is set once the batch is sent.
+func (h *Hydro) GetDiscountedRateCall(opts *web3.CallOpts, rate **big.Int, user common.Address) *web3.ContractCall {
+	return h.NewCall(opts, rate, "getDiscountedRate", user)
+}
+
+// GetHydroTokenAddress calls getHydroTokenAddress().
+func (h *Hydro) GetHydroTokenAddress(opts *web3.CallOpts) (hydroTokenAddress common.Address, err error) {
+	err = h.Query(opts, &hydroTokenAddress, "getHydroTokenAddress")
+	return
+}
+
+// GetHydroTokenAddressCall is GetHydroTokenAddress for BatchQuery, hydroTokenAddress is set once the batch is sent.
+func (h *Hydro) GetHydroTokenAddressCall(opts *web3.CallOpts, hydroTokenAddress *common.Address) *web3.ContractCall {
+	return h.NewCall(opts, hydroTokenAddress, "getHydroTokenAddress")
+}
+
+// GetIndex calls getIndex(address).
+func (h *Hydro) GetIndex(opts *web3.CallOpts, asset common.Address) (supplyIndex *big.Int, borrowIndex *big.Int, err error) {
+	var outputs struct {
+		SupplyIndex *big.Int
+		BorrowIndex *big.Int
+	}
+	err = h.Query(opts, &outputs, "getIndex", asset)
+	return outputs.SupplyIndex, outputs.BorrowIndex, err
+}
+
+// GetInsuranceBalance calls getInsuranceBalance(address).
+func (h *Hydro) GetInsuranceBalance(opts *web3.CallOpts, asset common.Address) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getInsuranceBalance", asset)
+	return
+}
+
+// GetInsuranceBalanceCall is GetInsuranceBalance for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetInsuranceBalanceCall(opts *web3.CallOpts, amount **big.Int, asset common.Address) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getInsuranceBalance", asset)
+}
+
+// GetInterestRates calls getInterestRates(address,uint256).
+func (h *Hydro) GetInterestRates(opts *web3.CallOpts, asset common.Address, extraBorrowAmount *big.Int) (borrowInterestRate *big.Int, supplyInterestRate *big.Int, err error) {
+	var outputs struct {
+		BorrowInterestRate *big.Int
+		SupplyInterestRate *big.Int
+	}
+	err = h.Query(opts, &outputs, "getInterestRates", asset, extraBorrowAmount)
+	return outputs.BorrowInterestRate, outputs.SupplyInterestRate, err
+}
+
+// GetMarket calls getMarket(uint16).
+func (h *Hydro) GetMarket(opts *web3.CallOpts, marketID uint16) (market Market, err error) {
+	err = h.Query(opts, &market, "getMarket", marketID)
+	return
+}
+
+// GetMarketCall is GetMarket for BatchQuery, market is set once the batch is sent.
+func (h *Hydro) GetMarketCall(opts *web3.CallOpts, market *Market, marketID uint16) *web3.ContractCall {
+	return h.NewCall(opts, market, "getMarket", marketID)
+}
+
+// GetMarketTransferableAmount calls getMarketTransferableAmount(uint16,address,address).
+func (h *Hydro) GetMarketTransferableAmount(opts *web3.CallOpts, marketID uint16, asset common.Address, user common.Address) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getMarketTransferableAmount", marketID, asset, user)
+	return
+}
+
+// GetMarketTransferableAmountCall is GetMarketTransferableAmount for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetMarketTransferableAmountCall(opts *web3.CallOpts, amount **big.Int, marketID uint16, asset common.Address, user common.Address) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getMarketTransferableAmount", marketID, asset, user)
+}
+
+// GetOrderFilledAmount calls getOrderFilledAmount(bytes32).
+func (h *Hydro) GetOrderFilledAmount(opts *web3.CallOpts, orderHash [32]byte) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getOrderFilledAmount", orderHash)
+	return
+}
+
+// GetOrderFilledAmountCall is GetOrderFilledAmount for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetOrderFilledAmountCall(opts *web3.CallOpts, amount **big.Int, orderHash [32]byte) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getOrderFilledAmount", orderHash)
+}
+
+// GetPoolCashableAmount calls getPoolCashableAmount(address).
+func (h *Hydro) GetPoolCashableAmount(opts *web3.CallOpts, asset common.Address) (cashableAmount *big.Int, err error) {
+	err = h.Query(opts, &cashableAmount, "getPoolCashableAmount", asset)
+	return
+}
+
+// GetPoolCashableAmountCall is GetPoolCashableAmount for BatchQuery, cashableAmount is set once the batch is sent.
+func (h *Hydro) GetPoolCashableAmountCall(opts *web3.CallOpts, cashableAmount **big.Int, asset common.Address) *web3.ContractCall {
+	return h.NewCall(opts, cashableAmount, "getPoolCashableAmount", asset)
+}
+
+// GetTotalBorrow calls getTotalBorrow(address).
+func (h *Hydro) GetTotalBorrow(opts *web3.CallOpts, asset common.Address) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getTotalBorrow", asset)
+	return
+}
+
+// GetTotalBorrowCall is GetTotalBorrow for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetTotalBorrowCall(opts *web3.CallOpts, amount **big.Int, asset common.Address) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getTotalBorrow", asset)
+}
+
+// GetTotalSupply calls getTotalSupply(address).
+func (h *Hydro) GetTotalSupply(opts *web3.CallOpts, asset common.Address) (amount *big.Int, err error) {
+	err = h.Query(opts, &amount, "getTotalSupply", asset)
+	return
+}
+
+// GetTotalSupplyCall is GetTotalSupply for BatchQuery, amount is set once the batch is sent.
+func (h *Hydro) GetTotalSupplyCall(opts *web3.CallOpts, amount **big.Int, asset common.Address) *web3.ContractCall {
+	return h.NewCall(opts, amount, "getTotalSupply", asset)
+}
+
+// IsAccountLiquidatable calls isAccountLiquidatable(address,uint16).
+func (h *Hydro) IsAccountLiquidatable(opts *web3.CallOpts, user common.Address, marketID uint16) (isLiquidatable bool, err error) {
+	err = h.Query(opts, &isLiquidatable, "isAccountLiquidatable", user, marketID)
+	return
+}
+
+// IsAccountLiquidatableCall is IsAccountLiquidatable for BatchQuery, isLiquidatable is set once the batch is sent.
+func (h *Hydro) IsAccountLiquidatableCall(opts *web3.CallOpts, isLiquidatable *bool, user common.Address, marketID uint16) *web3.ContractCall {
+	return h.NewCall(opts, isLiquidatable, "isAccountLiquidatable", user, marketID)
+}
+
+// IsOrderCancelled calls isOrderCancelled(bytes32).
+func (h *Hydro) IsOrderCancelled(opts *web3.CallOpts, orderHash [32]byte) (isCancelled bool, err error) {
+	err = h.Query(opts, &isCancelled, "isOrderCancelled", orderHash)
+	return
+}
+
+// IsOrderCancelledCall is IsOrderCancelled for BatchQuery, isCancelled is set once the batch is sent.
+func (h *Hydro) IsOrderCancelledCall(opts *web3.CallOpts, isCancelled *bool, orderHash [32]byte) *web3.ContractCall {
+	return h.NewCall(opts, isCancelled, "isOrderCancelled", orderHash)
+}
+
+// IsOwner calls isOwner().
+func (h *Hydro) IsOwner(opts *web3.CallOpts) (out bool, err error) {
+	err = h.Query(opts, &out, "isOwner")
+	return
+}
+
+// IsOwnerCall is IsOwner for BatchQuery, out is set once the batch is sent.
+func (h *Hydro) IsOwnerCall(opts *web3.CallOpts, out *bool) *web3.ContractCall {
+	return h.NewCall(opts, out, "isOwner")
+}
+
+// IsParticipant calls isParticipant(address).
+func (h *Hydro) IsParticipant(opts *web3.CallOpts, relayer common.Address) (result bool, err error) {
+	err = h.Query(opts, &result, "isParticipant", relayer)
+	return
+}
+
+// IsParticipantCall is IsParticipant for BatchQuery, result is set once the batch is sent.
+func (h *Hydro) IsParticipantCall(opts *web3.CallOpts, result *bool, relayer common.Address) *web3.ContractCall {
+	return h.NewCall(opts, result, "isParticipant", relayer)
+}
+
+// IsValidSignature calls isValidSignature(bytes32,address,(bytes32,bytes32,bytes32)).
+func (h *Hydro) IsValidSignature(opts *web3.CallOpts, hash [32]byte, signerAddress common.Address, signature Signature) (isValid bool, err error) {
+	err = h.Query(opts, &isValid, "isValidSignature", hash, signerAddress, signature)
+	return
+}
+
+// IsValidSignatureCall is IsValidSignature for BatchQuery, isValid is set once the batch is sent.
+func (h *Hydro) IsValidSignatureCall(opts *web3.CallOpts, isValid *bool, hash [32]byte, signerAddress common.Address, signature Signature) *web3.ContractCall {
+	return h.NewCall(opts, isValid, "isValidSignature", hash, signerAddress, signature)
+}
+
+// JoinIncentiveSystem signs a transaction calling joinIncentiveSystem(), it is not sent.
+func (h *Hydro) JoinIncentiveSystem(tx *web3.SendTxParams) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "joinIncentiveSystem")
+}
+
+// LiquidateAccount signs a transaction calling liquidateAccount(address,uint16), it is not sent.
+func (h *Hydro) LiquidateAccount(tx *web3.SendTxParams, user common.Address, marketID uint16) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "liquidateAccount", user, marketID)
+}
+
+// MarketBalanceOf calls marketBalanceOf(uint16,address,address).
+func (h *Hydro) MarketBalanceOf(opts *web3.CallOpts, marketID uint16, asset common.Address, user common.Address) (balance *big.Int, err error) {
+	err = h.Query(opts, &balance, "marketBalanceOf", marketID, asset, user)
+	return
+}
+
+// MarketBalanceOfCall is MarketBalanceOf for BatchQuery, balance is set once the batch is sent.
+func (h *Hydro) MarketBalanceOfCall(opts *web3.CallOpts, balance **big.Int, marketID uint16, asset common.Address, user common.Address) *web3.ContractCall {
+	return h.NewCall(opts, balance, "marketBalanceOf", marketID, asset, user)
+}
+
+// MatchOrders signs a transaction calling matchOrders(((address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32)),(address,uint256,uint256,uint256,bytes32,(bytes32,bytes32,bytes32))[],uint256[],(address,address,address))), it is not sent.
+func (h *Hydro) MatchOrders(tx *web3.SendTxParams, params Params) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "matchOrders", params)
+}
+
+// Owner calls owner().
+func (h *Hydro) Owner(opts *web3.CallOpts) (out common.Address, err error) {
+	err = h.Query(opts, &out, "owner")
+	return
+}
+
+// OwnerCall is Owner for BatchQuery, out is set once the batch is sent.
+func (h *Hydro) OwnerCall(opts *web3.CallOpts, out *common.Address) *web3.ContractCall {
+	return h.NewCall(opts, out, "owner")
+}
+
+// RenounceOwnership signs a transaction calling renounceOwnership(), it is not sent.
+func (h *Hydro) RenounceOwnership(tx *web3.SendTxParams) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "renounceOwnership")
+}
+
+// RevokeDelegate signs a transaction calling revokeDelegate(address), it is not sent.
+func (h *Hydro) RevokeDelegate(tx *web3.SendTxParams, delegate common.Address) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "revokeDelegate", delegate)
+}
+
+// SetMarketBorrowUsability signs a transaction calling setMarketBorrowUsability(uint16,bool), it is not sent.
+func (h *Hydro) SetMarketBorrowUsability(tx *web3.SendTxParams, marketID uint16, usability bool) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "setMarketBorrowUsability", marketID, usability)
+}
+
+// TransferOwnership signs a transaction calling transferOwnership(address), it is not sent.
+func (h *Hydro) TransferOwnership(tx *web3.SendTxParams, newOwner common.Address) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "transferOwnership", newOwner)
+}
+
+// UpdateAsset signs a transaction calling updateAsset(address,address,address), it is not sent.
+func (h *Hydro) UpdateAsset(tx *web3.SendTxParams, asset common.Address, oracleAddress common.Address, interestModelAddress common.Address) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "updateAsset", asset, oracleAddress, interestModelAddress)
+}
+
+// UpdateAuctionInitiatorRewardRatio signs a transaction calling updateAuctionInitiatorRewardRatio(uint256), it is not sent.
+func (h *Hydro) UpdateAuctionInitiatorRewardRatio(tx *web3.SendTxParams, newInitiatorRewardRatio *big.Int) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "updateAuctionInitiatorRewardRatio", newInitiatorRewardRatio)
+}
+
+// UpdateDiscountConfig signs a transaction calling updateDiscountConfig(bytes32), it is not sent.
+func (h *Hydro) UpdateDiscountConfig(tx *web3.SendTxParams, newConfig [32]byte) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "updateDiscountConfig", newConfig)
+}
+
+// UpdateInsuranceRatio signs a transaction calling updateInsuranceRatio(uint256), it is not sent.
+func (h *Hydro) UpdateInsuranceRatio(tx *web3.SendTxParams, newInsuranceRatio *big.Int) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "updateInsuranceRatio", newInsuranceRatio)
+}
+
+// UpdateMarket signs a transaction calling updateMarket(uint16,uint256,uint256,uint256,uint256), it is not sent.
+func (h *Hydro) UpdateMarket(tx *web3.SendTxParams, marketID uint16, newAuctionRatioStart *big.Int, newAuctionRatioPerBlock *big.Int, newLiquidateRate *big.Int, newWithdrawRate *big.Int) (txHash string, rawTx string, err error) {
+	return h.Sign(tx, big.NewInt(0), "updateMarket", marketID, newAuctionRatioStart, newAuctionRatioPerBlock, newLiquidateRate, newWithdrawRate)
+}
+
+// FillAuctionEvent is a FillAuction(uint256,address,uint256,uint256,uint256,uint256) log.
+type FillAuctionEvent struct {
+	AuctionID        *big.Int
+	Bidder           common.Address
+	RepayDebt        *big.Int
+	BidderRepayDebt  *big.Int
+	BidderCollateral *big.Int
+	LeftDebt         *big.Int
+}
+
+// ParseFillAuction decodes a FillAuction log, it fails if the log is another event.
+func (h *Hydro) ParseFillAuction(log web3.Log) (event *FillAuctionEvent, err error) {
+	event = &FillAuctionEvent{}
+	if err = h.DecodeLog(event, "FillAuction", log); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// OwnershipTransferredEvent is a OwnershipTransferred(address,address) log.
+type OwnershipTransferredEvent struct {
+	PreviousOwner common.Address
+	NewOwner      common.Address
+}
+
+// ParseOwnershipTransferred decodes a OwnershipTransferred log, it fails if the log is another event.
+func (h *Hydro) ParseOwnershipTransferred(log web3.Log) (event *OwnershipTransferredEvent, err error) {
+	event = &OwnershipTransferredEvent{}
+	if err = h.DecodeLog(event, "OwnershipTransferred", log); err != nil {
+		return nil, err
+	}
+	return
+}
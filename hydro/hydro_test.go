@@ -0,0 +1,27 @@
+package hydro
+
+import (
+	"auctionBidder/utils"
+	"auctionBidder/web3/bindgen"
+	"io/ioutil"
+	"testing"
+)
+
+// same config as gen.go
+func TestBindingsUpToDate(t *testing.T) {
+	code, err := bindgen.Generate(bindgen.Config{
+		Package:   "hydro",
+		Type:      "Hydro",
+		Abi:       utils.HydroAbi,
+		AbiConst:  "utils.HydroAbi",
+		AbiImport: "auctionBidder/utils",
+		Source:    "web3/bindgen from utils.HydroAbi",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, _ := ioutil.ReadFile("hydro.go")
+	if string(code) != string(generated) {
+		t.Errorf("hydro.go is not generated from utils.HydroAbi, run go generate ./hydro")
+	}
+}
@@ -3,15 +3,15 @@ package main
 import (
 	"auctionBidder/cli"
 	"auctionBidder/client"
-	"auctionBidder/utils"
+	"auctionBidder/config"
+	"auctionBidder/history"
+	"auctionBidder/metrics"
+	"auctionBidder/storage"
 	"auctionBidder/web3"
-	"encoding/json"
-	"fmt"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
-	"io/ioutil"
 	"os"
+	"time"
 )
 
 func main() {
@@ -19,24 +19,46 @@ func main() {
 	defer func() {
 		if err != nil {
 			spew.Dump(err)
+			os.Exit(1)
 		}
 	}()
 
-	setEnv()
+	if len(os.Args) > 1 && os.Args[1] == "delegate" {
+		err = runDelegate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "pnl" {
+		err = runPnl(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err = runExport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backtest" {
+		err = runBacktest(os.Args[2:])
+		return
+	}
 
-	err = checkEnv()
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
 		logrus.Error(err)
 		return
 	}
+	cfg.Export()
 
-	err = utils.InitDb()
+	if cfg.Headless {
+		cli.SetupHeadlessLogging(cfg.LogLevel)
+	}
+
+	store, err := newStore(cfg)
 	if err != nil {
 		logrus.Error(err)
 		return
 	}
+	defer store.Close()
 
-	_, err = startBot()
+	_, err = startBot(cfg, store)
 	if err != nil {
 		logrus.Error(err)
 		return
@@ -44,122 +66,116 @@ func main() {
 	return
 }
 
-func startBot() (bot *cli.BidderBot, err error) {
-
-	privateKey := os.Getenv("PRIVATE_KEY")
-	maxSlippage, _ := decimal.NewFromString(os.Getenv("MAX_SLIPPAGE"))
-	ethereumNodeUrl := os.Getenv("ETHEREUM_NODE_URL")
-	minOrderValueUSD, _ := decimal.NewFromString(os.Getenv("MIN_ORDER_VALUE_USD"))
-	profitMargin, _ := decimal.NewFromString(os.Getenv("PROFIT_MARGIN"))
-	markets := os.Getenv("MARKETS")
+func newStore(cfg *config.Config) (store storage.Store, err error) {
+	if cfg.DatabaseUrl != "" {
+		return storage.NewPostgresStore(cfg.DatabaseUrl)
+	}
+	return storage.NewSqliteStore(cfg.SqlitePath)
+}
 
-	gasPriceLevel := os.Getenv("GAS_PRICE_LEVEL")
-	gasPriceTipsInGwei := 5
-	switch gasPriceLevel {
-	case "fast":
-		gasPriceTipsInGwei = 0
-	case "super-fast":
-		gasPriceTipsInGwei = 10
-	case "flash-boy":
-		gasPriceTipsInGwei = 25
+func newSigner(account *config.Account) web3.Signer {
+	if account.SignerUrl != "" {
+		return web3.NewRemoteSigner(account.SignerUrl, account.Address)
 	}
+	return web3.NewLocalSigner(account.PrivateKey)
+}
 
-	ddexClient, err := client.NewDdexClient(privateKey)
+func newAccount(account *config.Account) (botAccount *cli.Account, err error) {
+	signer := newSigner(account)
+
+	ddexClient, err := client.NewDdexClient(signer)
 	if err != nil {
 		return
 	}
 
-	bidderClient, err := client.NewBidderClient(privateKey, ddexClient.Assets, ddexClient.Markets)
+	bidderClient, err := client.NewBidderClient(signer, ddexClient.Assets, ddexClient.Markets)
 	if err != nil {
 		return
 	}
 
-	web3Client := web3.NewWeb3(ethereumNodeUrl)
-
-	bot = &cli.BidderBot{
-		bidderClient,
-		ddexClient,
-		web3Client.NewBlockChannel(),
-		maxSlippage,
-		minOrderValueUSD,
-		gasPriceTipsInGwei,
-		markets,
-		profitMargin,
+	botAccount = &cli.Account{
+		Name:           account.Name,
+		BidderClient:   bidderClient,
+		DdexClient:     ddexClient,
+		MaxBidValueUSD: account.MaxBidValueUSD,
 	}
-
-	go bot.Run()
-
-	err = cli.StartGui()
-
 	return
 }
 
-func setEnv() {
-	os.Setenv("CONFIGPATH", "/workingDir/config.json")
-	os.Setenv("SQLITEPATH", "/workingDir/auctionBidderSqlite")
-	os.Setenv("LOGPATH", "/workingDir")
-	os.Setenv("CHAIN_ID", "1")
-	os.Setenv("HYDRO_CONTRACT_ADDRESS", "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7")
-	os.Setenv("DDEX_URL", "https://api.ddex.io/v4")
+func startBot(cfg *config.Config, store storage.Store) (bot *cli.BidderBot, err error) {
+	var accounts []*cli.Account
+	for _, account := range cfg.Accounts {
+		var botAccount *cli.Account
+		botAccount, err = newAccount(account)
+		if err != nil {
+			return
+		}
+		logrus.Infof("bid with account %s %s", account.Name, account.Address)
+		accounts = append(accounts, botAccount)
+	}
 
-	// ropsten
-	if os.Getenv("NETWORK") == "ropsten" {
-		os.Setenv("CHAIN_ID", "3")
-		os.Setenv("HYDRO_CONTRACT_ADDRESS", "0x06898143DF04616a8A8F9614deb3B99Ba12b3096")
-		os.Setenv("DDEX_URL", "https://bfd-ropsten-59c1702d-api.intra.ddex.io/v4/")
+	var availableMarkets []string
+	for tradingPair := range accounts[0].DdexClient.Markets {
+		availableMarkets = append(availableMarkets, tradingPair)
+	}
+	err = cfg.ValidateMarkets(availableMarkets)
+	if err != nil {
+		return
 	}
 
-	loadEnv(os.Getenv("CONFIGPATH"))
-}
+	web3Client := web3.NewWeb3(cfg.EthereumNodeUrl)
 
-func loadEnv(filePath string) (err error) {
-	jsonFile, err := os.Open(filePath)
-	defer jsonFile.Close()
-	if err != nil {
+	bot = cli.NewBidderBot(
+		accounts,
+		web3Client.NewBlockChannel(),
+		cfg,
+		store,
+		cfg.Watch(time.Second),
+	)
+
+	if cfg.HistoryDir != "" {
+		if bot.Recorder, err = history.NewRecorder(cfg.HistoryDir); err != nil {
+			return
+		}
+		defer bot.Recorder.Close()
+		logrus.Infof("record orderbooks and auctions in %s", cfg.HistoryDir)
+	}
+
+	if err = bot.ResumeIntents(); err != nil {
 		return
 	}
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	var envs map[string]string
-	json.Unmarshal(byteValue, &envs)
-	for key, value := range envs {
-		os.Setenv(key, value)
+	if cfg.ReconcileBlocks > 0 {
+		if _, reconcileErr := bot.Reconcile(cfg.ReconcileBlocks); reconcileErr != nil {
+			logrus.Errorf("reconciliation failed, the bid history may be incomplete: %s", reconcileErr.Error())
+		}
 	}
-	return
-}
 
-func checkEnv() (err error) {
-	requiredEnvDefaultValue := map[string]string{
-		"PRIVATE_KEY":         "B7A0C9D2786FC4DD080EA5D619D36771AEB0C8C26C290AFD3451B92BA2B7BC2C",
-		"MAX_SLIPPAGE":        "0.05",
-		"ETHEREUM_NODE_URL":   "https://mainnet.infura.io/v3/37851992caeb4289aa749112fe798621",
-		"MIN_ORDER_VALUE_USD": "100",
-		"MARKETS":             "ETH-USDT,ETH-DAI",
-		"PROFIT_MARGIN":       "0.01",
-		"GAS_PRICE_LEVEL":     "fast",
-	}
-	if os.Getenv("NETWORK") == "ropsten" {
-		requiredEnvDefaultValue["ETHEREUM_NODE_URL"] = "https://ropsten.infura.io/v3/37851992caeb4289aa749112fe798621"
-		requiredEnvDefaultValue["MARKETS"] = "ETH-USDT6,TETH-DAI"
-	}
-	for envName, defaultValue := range requiredEnvDefaultValue {
-		if os.Getenv(envName) == "" {
-			fmt.Printf("Enter %s(default %s):", envName, defaultValue)
-			var input string
-			fmt.Scanln(&input)
-			if input == "" {
-				input = defaultValue
+	if cfg.MetricsAddr != "" {
+		go func() {
+			logrus.Infof("serve metrics at %s/metrics", cfg.MetricsAddr)
+			if serveErr := metrics.Serve(cfg.MetricsAddr); serveErr != nil {
+				logrus.Errorf("serve metrics failed: %s", serveErr.Error())
 			}
-			os.Setenv(envName, input)
-		}
-		requiredEnvDefaultValue[envName] = os.Getenv(envName)
+		}()
+	}
+
+	go bot.Run()
+
+	if cfg.ApiAddr != "" {
+		go func() {
+			logrus.Infof("serve api at %s", cfg.ApiAddr)
+			if serveErr := bot.ServeApi(cfg.ApiAddr, cfg.ApiToken); serveErr != nil {
+				logrus.Errorf("serve api failed: %s", serveErr.Error())
+			}
+		}()
 	}
 
-	f, err := os.OpenFile(os.Getenv("CONFIGPATH"), os.O_CREATE|os.O_WRONLY, 0600)
-	defer f.Close()
-	if err == nil {
-		envToWrite, _ := json.MarshalIndent(requiredEnvDefaultValue, "", "  ")
-		f.Write(envToWrite)
+	if cfg.Headless {
+		cli.WaitForSignal()
+	} else {
+		err = cli.StartGui()
 	}
+	bot.Stop()
 
 	return
 }
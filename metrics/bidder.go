@@ -0,0 +1,75 @@
+package metrics
+
+// metrics of the bidder bot, label values are bounded: markets, accounts, symbols, rpc and signer methods and ddex endpoints
+var (
+	AuctionsSeen = NewCounter(
+		"auction_bidder_auctions_seen_total",
+		"Auctions checked for a bid, once per block.",
+		"market")
+	AuctionsSkipped = NewCounter(
+		"auction_bidder_auctions_skipped_total",
+		"Auctions checked but not bid on, by reason.",
+		"market", "reason")
+	Bids = NewCounter(
+		"auction_bidder_bids_total",
+		"Bids by status: sent, failed to send, filled or reverted once mined.",
+		"market", "status")
+	HedgeSlippage = NewHistogram(
+		"auction_bidder_hedge_slippage_ratio",
+		"Shortfall of the hedge price from the price estimated by the orderbook when bidding.",
+		[]float64{-0.01, -0.001, 0, 0.001, 0.0025, 0.005, 0.01, 0.02, 0.05, 0.1},
+		"market")
+
+	RPCDuration = NewHistogram(
+		"auction_bidder_rpc_duration_seconds",
+		"Latency of the ethereum node json-rpc calls, a batch of calls has the method batch.",
+		DefaultBuckets,
+		"method")
+	RPCErrors = NewCounter(
+		"auction_bidder_rpc_errors_total",
+		"Failed ethereum node json-rpc calls, including json-rpc errors.",
+		"method")
+	SignerDuration = NewHistogram(
+		"auction_bidder_signer_duration_seconds",
+		"Latency of the remote signer json-rpc calls.",
+		DefaultBuckets,
+		"method")
+	SignerErrors = NewCounter(
+		"auction_bidder_signer_errors_total",
+		"Failed remote signer json-rpc calls, including rejected requests.",
+		"method")
+	DdexDuration = NewHistogram(
+		"auction_bidder_ddex_duration_seconds",
+		"Latency of the ddex api requests.",
+		DefaultBuckets,
+		"method", "endpoint")
+	DdexErrors = NewCounter(
+		"auction_bidder_ddex_errors_total",
+		"Failed ddex api requests.",
+		"method", "endpoint")
+
+	HeadBlock = NewGauge(
+		"auction_bidder_head_block",
+		"Latest block number of the ethereum node.")
+	ProcessedBlock = NewGauge(
+		"auction_bidder_processed_block",
+		"Latest block number handled by the bot.")
+	BlockLag = NewGaugeFunc(
+		"auction_bidder_block_lag",
+		"Blocks the bot is behind the ethereum node.",
+		func() float64 {
+			if ProcessedBlock.Value() == 0 {
+				return 0
+			}
+			return HeadBlock.Value() - ProcessedBlock.Value()
+		})
+
+	FreeBalance = NewGauge(
+		"auction_bidder_free_balance",
+		"Balance not locked by ddex orders.",
+		"account", "symbol")
+	PnlUSD = NewGauge(
+		"auction_bidder_pnl_usd",
+		"Cumulative pnl of the bid history: realized, unrealized, gas and fees.",
+		"kind")
+)
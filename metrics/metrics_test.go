@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	counter := NewCounter("test_requests_total", "Requests.", "method", "path")
+	counter.Inc("GET", `a"b`)
+	counter.Add(2, "GET", `a"b`)
+	counter.Inc("DELETE", "c")
+
+	histogram := NewHistogram("test_duration_seconds", "Latency.\nIn seconds.", []float64{0.1, 1}, "method")
+	histogram.Observe(0.05, "GET")
+	histogram.Observe(0.5, "GET")
+	histogram.Observe(3, "GET")
+
+	var buf bytes.Buffer
+	counter.write(&buf)
+	histogram.write(&buf)
+	expected := `# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{method="DELETE",path="c"} 1
+test_requests_total{method="GET",path="a\"b"} 3
+# HELP test_duration_seconds Latency.\nIn seconds.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="GET",le="0.1"} 1
+test_duration_seconds_bucket{method="GET",le="1"} 2
+test_duration_seconds_bucket{method="GET",le="+Inf"} 3
+test_duration_seconds_sum{method="GET"} 3.55
+test_duration_seconds_count{method="GET"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	gauge := NewGauge("test_block", "Block.")
+	gauge.Set(10)
+	lag := NewGaugeFunc("test_lag", "Lag.", func() float64 { return 12 - gauge.Value() })
+	buf.Reset()
+	lag.write(&buf)
+	if buf.String() != "# HELP test_lag Lag.\n# TYPE test_lag gauge\ntest_lag 2\n" {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"auctionBidder/client"
+	"auctionBidder/config"
+	"auctionBidder/pnl"
+	"auctionBidder/storage"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"os"
+	"text/tabwriter"
+)
+
+const pnlUsage = "usage: main pnl <day|week> [flags]"
+
+// print the realized pnl by day or week and the open inventory valued at the current ddex prices
+func runPnl(args []string) (err error) {
+	if len(args) < 1 || (args[0] != pnl.DAILY && args[0] != pnl.WEEKLY) {
+		return errors.New(pnlUsage)
+	}
+
+	cfg, err := config.LoadReadOnly(args[1:])
+	if err != nil {
+		return
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return
+	}
+	defer store.Close()
+
+	trades, err := store.QueryTrades(storage.Filter{})
+	if err != nil {
+		return
+	}
+	prices, err := client.GetAssetUSDPrices(cfg.DdexUrl)
+	if err != nil {
+		logrus.Warnf("get usd prices failed, open positions are not valued: %s", err.Error())
+		err = nil
+	}
+	report := pnl.Compute(trades, prices, args[0])
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "period\ttrades\treverted\tgross\tfees\tgas\trealized\t")
+	for _, p := range report.Periods {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", p.Label, p.Trades, p.Reverted, usd(p.GrossUSD), usd(p.FeesUSD), usd(p.GasUSD), usd(p.RealizedUSD))
+	}
+	fmt.Fprintf(w, "total\t%d\t\t%s\t%s\t%s\t%s\t\n", len(report.Trades), usd(report.GrossUSD), usd(report.FeesUSD), usd(report.GasUSD), usd(report.RealizedUSD))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "open\tamount\tcost\tvalue\tunrealized\t")
+	for _, p := range report.Positions {
+		if !p.Priced {
+			fmt.Fprintf(w, "%s\t%s\t%s\tno price\t\t\n", p.Symbol, p.Amount.String(), usd(p.CostUSD))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", p.Symbol, p.Amount.String(), usd(p.CostUSD), usd(p.ValueUSD), usd(p.UnrealizedUSD))
+	}
+	fmt.Fprintf(w, "total\t\t\t\t%s\t\n", usd(report.UnrealizedUSD))
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	if report.Unpriced > 0 {
+		fmt.Printf("\n%d bids were recorded without prices and are counted as 0\n", report.Unpriced)
+	}
+	return
+}
+
+func usd(d decimal.Decimal) string {
+	return "$" + d.StringFixed(2)
+}
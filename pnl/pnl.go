@@ -0,0 +1,223 @@
+package pnl
+
+import (
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DAILY  = "day"
+	WEEKLY = "week"
+)
+
+// Trade is the profit of one mined bid in USD, valued at the prices recorded when the bid was decided.
+type Trade struct {
+	BidID       int64
+	AuctionID   int64
+	Market      string
+	Time        time.Time // zero for bids recorded before the ledger had timestamps
+	Status      string
+	GrossUSD    decimal.Decimal // hedged collateral sold minus the debt repaid for it
+	FeesUSD     decimal.Decimal // ddex trading and gas fees of the hedge orders
+	GasUSD      decimal.Decimal
+	RealizedUSD decimal.Decimal // GrossUSD - FeesUSD - GasUSD
+	OpenSymbol  string
+	OpenAmount  decimal.Decimal // collateral received but not hedged
+	OpenCostUSD decimal.Decimal // debt repaid for the open collateral
+	Priced      bool            // false if the bid was recorded without prices, its usd values are zero
+}
+
+// Period aggregates the realized profit of the trades decided in a day or a week, UTC.
+type Period struct {
+	Label       string // 2019-10-19, 2019-W42, or unknown for bids without timestamps
+	Start       time.Time
+	Trades      int
+	Reverted    int
+	GrossUSD    decimal.Decimal
+	FeesUSD     decimal.Decimal
+	GasUSD      decimal.Decimal
+	RealizedUSD decimal.Decimal
+}
+
+// Position is the open inventory of a collateral, valued at the current price.
+type Position struct {
+	Symbol        string
+	Amount        decimal.Decimal
+	CostUSD       decimal.Decimal
+	ValueUSD      decimal.Decimal
+	UnrealizedUSD decimal.Decimal
+	Priced        bool // false if the current price is unknown, not counted in the unrealized total
+}
+
+type Report struct {
+	Trades        []*Trade
+	Periods       []*Period
+	Positions     []*Position
+	GrossUSD      decimal.Decimal
+	FeesUSD       decimal.Decimal
+	GasUSD        decimal.Decimal
+	RealizedUSD   decimal.Decimal
+	UnrealizedUSD decimal.Decimal
+	Unpriced      int // trades recorded without prices
+}
+
+// ComputeTrade returns the profit of a trade, nil if the bid was never mined.
+func ComputeTrade(t *storage.Trade) *Trade {
+	if t.Fill == nil {
+		return nil
+	}
+	bid, fill := t.Bid, t.Fill
+	trade := &Trade{
+		BidID:       bid.ID,
+		AuctionID:   bid.AuctionID,
+		Market:      bid.Market,
+		Status:      bid.Status,
+		GrossUSD:    decimal.Zero,
+		FeesUSD:     decimal.Zero,
+		GasUSD:      fill.GasCost.Mul(bid.EthPriceUSD),
+		OpenSymbol:  bid.CollateralSymbol,
+		OpenAmount:  decimal.Zero,
+		OpenCostUSD: decimal.Zero,
+		Priced:      bid.DebtPriceUSD.IsPositive() && bid.EthPriceUSD.IsPositive(),
+	}
+	if bid.CreatedAt > 0 {
+		trade.Time = time.Unix(0, bid.CreatedAt*int64(time.Millisecond)).UTC()
+	}
+
+	if fill.ReceiveCollateral.IsPositive() {
+		sold, received := decimal.Zero, decimal.Zero
+		for _, order := range t.HedgeOrders {
+			sold = sold.Add(order.SellAmount)
+			received = received.Add(order.ReceiveAmount)
+			trade.FeesUSD = trade.FeesUSD.Add(hedgeFeeUSD(bid, order))
+		}
+
+		// the debt repaid is split between the hedged and the open collateral
+		hedged := decimal.Min(sold.Div(fill.ReceiveCollateral), decimal.New(1, 0))
+		hedgedCost := fill.RepayDebt.Mul(hedged)
+		trade.GrossUSD = received.Sub(hedgedCost).Mul(bid.DebtPriceUSD)
+		if fill.ReceiveCollateral.GreaterThan(sold) {
+			trade.OpenAmount = fill.ReceiveCollateral.Sub(sold)
+			trade.OpenCostUSD = fill.RepayDebt.Sub(hedgedCost).Mul(bid.DebtPriceUSD)
+		}
+	}
+
+	trade.RealizedUSD = trade.GrossUSD.Sub(trade.FeesUSD).Sub(trade.GasUSD)
+	return trade
+}
+
+// ddex charges fees in the quote asset: a rate of the traded quote amount plus a flat gas fee
+func hedgeFeeUSD(bid *storage.Bid, order *storage.HedgeOrder) decimal.Decimal {
+	market := order.Market
+	if market == "" {
+		market = bid.Market
+	}
+	pair := strings.Split(market, "-")
+	if len(pair) != 2 {
+		return decimal.Zero
+	}
+
+	quoteAmount := order.SellAmount
+	if order.Side == utils.SELL {
+		quoteAmount = order.ReceiveAmount
+	}
+	fee := quoteAmount.Mul(order.FeeRate).Add(order.GasFee)
+
+	if pair[1] == bid.DebtSymbol {
+		return fee.Mul(bid.DebtPriceUSD)
+	}
+	return fee.Mul(bid.CollateralPriceUSD)
+}
+
+// Compute aggregates the trades by period and values the open inventory at the current usd prices.
+func Compute(trades []*storage.Trade, prices map[string]decimal.Decimal, period string) (report *Report) {
+	report = &Report{
+		GrossUSD:      decimal.Zero,
+		FeesUSD:       decimal.Zero,
+		GasUSD:        decimal.Zero,
+		RealizedUSD:   decimal.Zero,
+		UnrealizedUSD: decimal.Zero,
+	}
+	periods := map[string]*Period{}
+	positions := map[string]*Position{}
+
+	for _, t := range trades {
+		trade := ComputeTrade(t)
+		if trade == nil {
+			continue
+		}
+		report.Trades = append(report.Trades, trade)
+		if !trade.Priced {
+			report.Unpriced++
+		}
+		report.GrossUSD = report.GrossUSD.Add(trade.GrossUSD)
+		report.FeesUSD = report.FeesUSD.Add(trade.FeesUSD)
+		report.GasUSD = report.GasUSD.Add(trade.GasUSD)
+		report.RealizedUSD = report.RealizedUSD.Add(trade.RealizedUSD)
+
+		label, start := periodOf(trade.Time, period)
+		p, ok := periods[label]
+		if !ok {
+			p = &Period{Label: label, Start: start, GrossUSD: decimal.Zero, FeesUSD: decimal.Zero, GasUSD: decimal.Zero, RealizedUSD: decimal.Zero}
+			periods[label] = p
+			report.Periods = append(report.Periods, p)
+		}
+		p.Trades++
+		if trade.Status == storage.BIDREVERTED {
+			p.Reverted++
+		}
+		p.GrossUSD = p.GrossUSD.Add(trade.GrossUSD)
+		p.FeesUSD = p.FeesUSD.Add(trade.FeesUSD)
+		p.GasUSD = p.GasUSD.Add(trade.GasUSD)
+		p.RealizedUSD = p.RealizedUSD.Add(trade.RealizedUSD)
+
+		if trade.OpenAmount.IsPositive() {
+			position, ok := positions[trade.OpenSymbol]
+			if !ok {
+				position = &Position{Symbol: trade.OpenSymbol, Amount: decimal.Zero, CostUSD: decimal.Zero}
+				positions[trade.OpenSymbol] = position
+				report.Positions = append(report.Positions, position)
+			}
+			position.Amount = position.Amount.Add(trade.OpenAmount)
+			position.CostUSD = position.CostUSD.Add(trade.OpenCostUSD)
+		}
+	}
+
+	for _, position := range report.Positions {
+		price, ok := prices[position.Symbol]
+		position.Priced = ok && price.IsPositive()
+		position.ValueUSD = position.Amount.Mul(price)
+		position.UnrealizedUSD = decimal.Zero
+		if position.Priced {
+			position.UnrealizedUSD = position.ValueUSD.Sub(position.CostUSD)
+			report.UnrealizedUSD = report.UnrealizedUSD.Add(position.UnrealizedUSD)
+		}
+	}
+
+	sort.SliceStable(report.Periods, func(i, j int) bool {
+		return report.Periods[i].Start.Before(report.Periods[j].Start)
+	})
+	sort.Slice(report.Positions, func(i, j int) bool {
+		return report.Positions[i].Symbol < report.Positions[j].Symbol
+	})
+	return
+}
+
+func periodOf(t time.Time, period string) (label string, start time.Time) {
+	if t.IsZero() {
+		return "unknown", t
+	}
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if period != WEEKLY {
+		return start.Format("2006-01-02"), start
+	}
+	// weeks start on monday, labeled by iso week
+	start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	year, week := start.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week), start
+}
@@ -0,0 +1,90 @@
+package pnl
+
+import (
+	"auctionBidder/storage"
+	"auctionBidder/utils"
+	"github.com/shopspring/decimal"
+	"testing"
+	"time"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func at(s string) int64 {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func bid(id int64, createdAt int64, status string) *storage.Bid {
+	return &storage.Bid{ID: id, CreatedAt: createdAt, Market: "ETH-USDT", DebtSymbol: "USDT", CollateralSymbol: "ETH",
+		DebtPriceUSD: d("1"), CollateralPriceUSD: d("180"), EthPriceUSD: d("180"), Status: status}
+}
+
+func TestCompute(t *testing.T) {
+	legacy := &storage.Bid{ID: 5, Market: "ETH-USDT", DebtSymbol: "USDT", CollateralSymbol: "ETH", Status: storage.BIDFILLED}
+	trades := []*storage.Trade{
+		{
+			Bid:  bid(1, at("2019-10-14T10:00:00Z"), storage.BIDFILLED),
+			Fill: &storage.Fill{RepayDebt: d("1000"), ReceiveCollateral: d("6"), GasCost: d("0.01")},
+			HedgeOrders: []*storage.HedgeOrder{
+				{Market: "ETH-USDT", Side: utils.SELL, SellAmount: d("6"), ReceiveAmount: d("1080"), FeeRate: d("0.001"), GasFee: d("1")},
+			},
+		},
+		{
+			// half of the collateral is still open
+			Bid:  bid(2, at("2019-10-16T23:59:00Z"), storage.BIDFILLED),
+			Fill: &storage.Fill{RepayDebt: d("600"), ReceiveCollateral: d("4")},
+			HedgeOrders: []*storage.HedgeOrder{
+				{Market: "ETH-USDT", Side: utils.SELL, SellAmount: d("2"), ReceiveAmount: d("360")},
+			},
+		},
+		{
+			Bid:  bid(3, at("2019-10-21T00:01:00Z"), storage.BIDREVERTED),
+			Fill: &storage.Fill{GasCost: d("0.005")},
+		},
+		{Bid: bid(4, at("2019-10-21T00:02:00Z"), storage.BIDFAILED)},
+		{Bid: legacy, Fill: &storage.Fill{RepayDebt: d("100"), ReceiveCollateral: d("1")}},
+	}
+
+	report := Compute(trades, map[string]decimal.Decimal{"ETH": d("170")}, WEEKLY)
+	if len(report.Trades) != 4 || report.Unpriced != 1 {
+		t.Fatalf("unexpected trades %d, unpriced %d", len(report.Trades), report.Unpriced)
+	}
+	if !report.Trades[0].RealizedUSD.Equal(d("76.12")) || !report.Trades[0].FeesUSD.Equal(d("2.08")) || !report.Trades[0].GasUSD.Equal(d("1.8")) {
+		t.Errorf("unexpected hedged trade %+v", report.Trades[0])
+	}
+	if !report.RealizedUSD.Equal(d("135.22")) || !report.UnrealizedUSD.Equal(d("210")) {
+		t.Errorf("realized %s unrealized %s", report.RealizedUSD, report.UnrealizedUSD)
+	}
+
+	expect := []struct {
+		label    string
+		trades   int
+		reverted int
+		realized string
+	}{
+		{"unknown", 1, 0, "0"},
+		{"2019-W42", 2, 0, "136.12"},
+		{"2019-W43", 1, 1, "-0.9"},
+	}
+	if len(report.Periods) != len(expect) {
+		t.Fatalf("unexpected periods %+v", report.Periods)
+	}
+	for i, e := range expect {
+		p := report.Periods[i]
+		if p.Label != e.label || p.Trades != e.trades || p.Reverted != e.reverted || !p.RealizedUSD.Equal(d(e.realized)) {
+			t.Errorf("period %d: %+v, expect %+v", i, p, e)
+		}
+	}
+
+	if len(report.Positions) != 1 || !report.Positions[0].Amount.Equal(d("3")) || !report.Positions[0].CostUSD.Equal(d("300")) {
+		t.Errorf("unexpected positions %+v", report.Positions)
+	}
+
+	report = Compute(trades, nil, DAILY)
+	if len(report.Periods) != 4 || report.Periods[1].Label != "2019-10-14" || report.Positions[0].Priced || !report.UnrealizedUSD.IsZero() {
+		t.Errorf("unexpected daily report %+v %+v", report.Periods, report.Positions)
+	}
+}
@@ -0,0 +1,364 @@
+package simulator
+
+import (
+	"auctionBidder/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Order is an order on ddex. Market buy orders are sized in the quote asset.
+type Order struct {
+	ID           string
+	Account      string
+	MarketID     string
+	Side         string
+	Type         string
+	Price        decimal.Decimal
+	Amount       decimal.Decimal
+	FilledAmount decimal.Decimal
+	AvgPrice     decimal.Decimal
+	Open         bool
+	Placed       bool
+	CreatedAt    int64
+}
+
+// Orders returns copies of the placed orders of the account, oldest first.
+func (s *Simulator) Orders(account string) (orders []Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.orderIDs {
+		if order := s.orders[id]; order.Placed && order.Account == strings.ToLower(account) {
+			orders = append(orders, *order)
+		}
+	}
+	return
+}
+
+type ddexResponse struct {
+	Status int         `json:"status"`
+	Desc   string      `json:"desc"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+func (s *Simulator) serveDdex(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	// the account is the first part of "address#message#signature", signatures are not checked
+	account := strings.ToLower(strings.Split(r.Header.Get("Hydro-Authentication"), "#")[0])
+
+	s.mu.Lock()
+	data, err := s.handleDdex(r, path, account)
+	s.mu.Unlock()
+	response := ddexResponse{Desc: "success", Data: data}
+	if err != nil {
+		response = ddexResponse{Status: -1, Desc: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Simulator) handleDdex(r *http.Request, path []string, account string) (data interface{}, err error) {
+	// order ids and markets are replaced by a placeholder
+	parts := append([]string{}, path...)
+	if len(parts) > 1 && (parts[0] == "markets" || parts[0] == "orders" && parts[1] != "build") {
+		parts[1] = ":id"
+	}
+	route := r.Method + " " + strings.Join(parts, "/")
+	switch route {
+	case "GET markets":
+		return map[string]interface{}{"markets": s.marketsJSON()}, nil
+	case "GET assets":
+		return map[string]interface{}{"assets": s.assetsJSON()}, nil
+	case "GET markets/:id/orderbook":
+		market, ok := s.markets[path[1]]
+		if !ok {
+			return nil, errors.New("market not found")
+		}
+		return map[string]interface{}{"orderBook": orderbookJSON(market, r.URL.Query().Get("level") == "1")}, nil
+	}
+
+	if account == "" {
+		return nil, errors.New("unauthorized")
+	}
+	switch route {
+	case "POST orders/build":
+		var body struct {
+			MarketId  string          `json:"marketId"`
+			Side      string          `json:"side"`
+			OrderType string          `json:"orderType"`
+			Price     decimal.Decimal `json:"price"`
+			Amount    decimal.Decimal `json:"amount"`
+		}
+		if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return
+		}
+		if _, ok := s.markets[body.MarketId]; !ok {
+			return nil, errors.New("market not found")
+		}
+		id := fmt.Sprintf("0x%064x", len(s.orderIDs)+1)
+		s.orders[id] = &Order{
+			ID:        id,
+			Account:   account,
+			MarketID:  body.MarketId,
+			Side:      body.Side,
+			Type:      body.OrderType,
+			Price:     body.Price,
+			Amount:    body.Amount,
+			CreatedAt: utils.MillisecondTimestamp(),
+		}
+		s.orderIDs = append(s.orderIDs, id)
+		return map[string]interface{}{"order": map[string]string{"id": id}}, nil
+	case "POST orders":
+		var body struct {
+			OrderId string `json:"orderId"`
+		}
+		if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return
+		}
+		order, ok := s.orders[body.OrderId]
+		if !ok || order.Account != account || order.Placed {
+			return nil, errors.New("order not found")
+		}
+		if err = s.placeOrder(order); err != nil {
+			return
+		}
+		return map[string]interface{}{"order": s.orderJSON(order)}, nil
+	case "GET orders/:id":
+		order, ok := s.orders[path[1]]
+		if !ok || order.Account != account || !order.Placed {
+			return nil, errors.New("order not found")
+		}
+		return map[string]interface{}{"order": s.orderJSON(order)}, nil
+	case "GET orders":
+		orders := []interface{}{}
+		for i := len(s.orderIDs) - 1; i >= 0; i-- {
+			order := s.orders[s.orderIDs[i]]
+			if order.Placed && order.Account == account && order.MarketID == r.URL.Query().Get("marketId") {
+				orders = append(orders, s.orderJSON(order))
+			}
+		}
+		page, perPage := queryInt(r, "page", 1), queryInt(r, "perPage", 20)
+		from, to := (page-1)*perPage, page*perPage
+		if from > len(orders) {
+			from = len(orders)
+		}
+		if to > len(orders) {
+			to = len(orders)
+		}
+		return map[string]interface{}{
+			"totalCount":  len(orders),
+			"totalPages":  (len(orders) + perPage - 1) / perPage,
+			"currentPage": page,
+			"orders":      orders[from:to],
+		}, nil
+	case "DELETE orders/:id":
+		order, ok := s.orders[path[1]]
+		if !ok || order.Account != account || !order.Placed {
+			return nil, errors.New("order not found")
+		}
+		order.Open = false
+		return nil, nil
+	case "DELETE orders":
+		for _, order := range s.orders {
+			if order.Account == account && order.MarketID == r.URL.Query().Get("marketId") {
+				order.Open = false
+			}
+		}
+		return nil, nil
+	case "GET account/lockedBalances":
+		lockedBalances := []interface{}{}
+		for _, asset := range s.assets {
+			if locked := s.locked(account, asset.Symbol); locked.IsPositive() {
+				lockedBalances = append(lockedBalances, map[string]interface{}{
+					"symbol":       asset.Symbol,
+					"assetAddress": asset.Address,
+					"walletType":   "trading",
+					"amount":       toRaw(locked, asset).String(),
+				})
+			}
+		}
+		return map[string]interface{}{"lockedBalances": lockedBalances}, nil
+	}
+	return nil, fmt.Errorf("%s /%s not found", r.Method, strings.Join(path, "/"))
+}
+
+// balance held by the open limit orders of the account
+func (s *Simulator) locked(account string, symbol string) (locked decimal.Decimal) {
+	for _, order := range s.orders {
+		if !order.Open || order.Account != account {
+			continue
+		}
+		market := s.markets[order.MarketID]
+		if order.Side == utils.SELL && market.Base.Symbol == symbol {
+			locked = locked.Add(order.Amount)
+		} else if order.Side == utils.BUY && market.Quote.Symbol == symbol {
+			locked = locked.Add(order.Amount.Mul(order.Price))
+		}
+	}
+	return
+}
+
+// limit orders rest in the book without matching, market orders take the orders up to the price limit
+// and the rest of the order is canceled. Fees are paid in the quote asset.
+func (s *Simulator) placeOrder(order *Order) error {
+	market := s.markets[order.MarketID]
+	if order.Type != "market" {
+		symbol, amount := market.Base.Symbol, order.Amount
+		if order.Side == utils.BUY {
+			symbol, amount = market.Quote.Symbol, order.Amount.Mul(order.Price)
+		}
+		if s.balance(order.Account, symbol).Sub(s.locked(order.Account, symbol)).LessThan(amount) {
+			return errors.New("insufficient balance")
+		}
+		order.Placed, order.Open = true, true
+		return nil
+	}
+
+	base, quote := market.Base.Symbol, market.Quote.Symbol
+	var baseAmount, quoteAmount = decimal.Zero, decimal.Zero
+	if order.Side == utils.SELL {
+		if s.balance(order.Account, base).Sub(s.locked(order.Account, base)).LessThan(order.Amount) {
+			return errors.New("insufficient balance")
+		}
+		left := order.Amount
+		for _, bid := range market.Bids {
+			if !left.IsPositive() || bid.Price.LessThan(order.Price) {
+				break
+			}
+			amount := decimal.Min(left, bid.Amount)
+			bid.Amount = bid.Amount.Sub(amount)
+			left = left.Sub(amount)
+			baseAmount, quoteAmount = baseAmount.Add(amount), quoteAmount.Add(amount.Mul(bid.Price))
+		}
+		fee := quoteAmount.Mul(market.TakerFeeRate)
+		s.setBalance(order.Account, base, s.balance(order.Account, base).Sub(baseAmount))
+		s.setBalance(order.Account, quote, s.balance(order.Account, quote).Add(quoteAmount).Sub(fee))
+		order.FilledAmount = baseAmount
+	} else {
+		if s.balance(order.Account, quote).Sub(s.locked(order.Account, quote)).LessThan(order.Amount.Mul(decimal.New(1, 0).Add(market.TakerFeeRate))) {
+			return errors.New("insufficient balance")
+		}
+		left := order.Amount
+		for _, ask := range market.Asks {
+			if !left.IsPositive() || ask.Price.GreaterThan(order.Price) {
+				break
+			}
+			amount := decimal.Min(left.Div(ask.Price), ask.Amount)
+			ask.Amount = ask.Amount.Sub(amount)
+			left = left.Sub(amount.Mul(ask.Price))
+			baseAmount, quoteAmount = baseAmount.Add(amount), quoteAmount.Add(amount.Mul(ask.Price))
+		}
+		fee := quoteAmount.Mul(market.TakerFeeRate)
+		s.setBalance(order.Account, quote, s.balance(order.Account, quote).Sub(quoteAmount).Sub(fee))
+		s.setBalance(order.Account, base, s.balance(order.Account, base).Add(baseAmount))
+		order.FilledAmount = quoteAmount
+	}
+	market.Bids, market.Asks = removeEmpty(market.Bids), removeEmpty(market.Asks)
+	if baseAmount.IsPositive() {
+		order.AvgPrice = quoteAmount.Div(baseAmount)
+	}
+	order.Placed = true
+	return nil
+}
+
+// a positive int query param, or the default
+func queryInt(r *http.Request, name string, defaultValue int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+	return value
+}
+
+func removeEmpty(levels []*Level) (left []*Level) {
+	for _, level := range levels {
+		if level.Amount.IsPositive() {
+			left = append(left, level)
+		}
+	}
+	return
+}
+
+func (s *Simulator) orderJSON(order *Order) map[string]interface{} {
+	available := decimal.Zero
+	if order.Open {
+		available = order.Amount.Sub(order.FilledAmount)
+	}
+	status := "closed"
+	if order.Open {
+		status = "pending"
+	}
+	return map[string]interface{}{
+		"id":              order.ID,
+		"type":            order.Type,
+		"status":          status,
+		"amount":          order.Amount.String(),
+		"availableAmount": available.String(),
+		"pendingAmount":   "0",
+		"canceledAmount":  order.Amount.Sub(order.FilledAmount).Sub(available).String(),
+		"confirmedAmount": order.FilledAmount.String(),
+		"price":           order.Price.String(),
+		"averagePrice":    order.AvgPrice.String(),
+		"side":            order.Side,
+		"takerFeeRate":    s.markets[order.MarketID].TakerFeeRate.String(),
+		"gasFeeAmount":    "0",
+		"account":         order.Account,
+		"createdAt":       order.CreatedAt,
+		"marketId":        order.MarketID,
+	}
+}
+
+func (s *Simulator) marketsJSON() (markets []interface{}) {
+	markets = []interface{}{}
+	for tradingPair, market := range s.markets {
+		markets = append(markets, map[string]interface{}{
+			"id":                 tradingPair,
+			"baseAsset":          market.Base.Symbol,
+			"baseAssetName":      market.Base.Symbol,
+			"baseAssetDecimals":  market.Base.Decimals,
+			"baseAssetAddress":   market.Base.Address,
+			"quoteAsset":         market.Quote.Symbol,
+			"quoteAssetName":     market.Quote.Symbol,
+			"quoteAssetDecimals": market.Quote.Decimals,
+			"quoteAssetAddress":  market.Quote.Address,
+			"pricePrecision":     5,
+			"priceDecimals":      8,
+			"amountDecimals":     8,
+			"asTakerFeeRate":     market.TakerFeeRate.String(),
+			"gasFeeAmount":       "0",
+		})
+	}
+	return
+}
+
+func (s *Simulator) assetsJSON() (assets []interface{}) {
+	assets = []interface{}{}
+	for _, asset := range s.assets {
+		assets = append(assets, map[string]interface{}{
+			"symbol":         asset.Symbol,
+			"address":        asset.Address,
+			"decimals":       asset.Decimals,
+			"oracleUSDPrice": asset.USDPrice.String(),
+		})
+	}
+	return
+}
+
+// level 1 is the best bid and ask only
+func orderbookJSON(market *Market, best bool) map[string]interface{} {
+	levels := func(levels []*Level) (json []map[string]string) {
+		json = []map[string]string{}
+		for i, level := range levels {
+			if best && i > 0 {
+				break
+			}
+			json = append(json, map[string]string{"price": level.Price.String(), "amount": level.Amount.String()})
+		}
+		return
+	}
+	return map[string]interface{}{"marketId": market.TradingPair(), "bids": levels(market.Bids), "asks": levels(market.Asks)}
+}
@@ -0,0 +1,525 @@
+package simulator
+
+import (
+	"auctionBidder/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/shopspring/decimal"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+var hydroAbi abi.ABI
+
+func init() {
+	var err error
+	if hydroAbi, err = abi.JSON(strings.NewReader(utils.HydroAbi)); err != nil {
+		panic(err)
+	}
+}
+
+var errReverted = errors.New("execution reverted")
+
+type transaction struct {
+	hash        string
+	from        string
+	nonce       uint64
+	gasPrice    *big.Int
+	input       []byte
+	blockNumber int64 // 0 while pending
+	index       int
+	reverted    bool
+	logs        []*log
+}
+
+type log struct {
+	index  int
+	topics []string
+	data   string
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	ID      json.RawMessage `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+func (s *Simulator) serveNode(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// a batch is an array of requests answered by an array of responses
+	var requests []rpcRequest
+	body = bytes.TrimSpace(body)
+	batch := len(body) > 0 && body[0] == '['
+	if batch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		requests = make([]rpcRequest, 1)
+		err = json.Unmarshal(body, &requests[0])
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var responses []rpcResponse
+	s.mu.Lock()
+	s.nodeRequests++
+	for _, request := range requests {
+		response := rpcResponse{ID: request.ID, JSONRPC: "2.0"}
+		if result, err := s.handleRpc(request.Method, request.Params); err != nil {
+			response.Error = &rpcError{-32000, err.Error()}
+		} else {
+			response.Result = result
+		}
+		responses = append(responses, response)
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(responses)
+	} else {
+		json.NewEncoder(w).Encode(responses[0])
+	}
+}
+
+func (s *Simulator) handleRpc(method string, params []json.RawMessage) (result interface{}, err error) {
+	var args []interface{}
+	for _, param := range params {
+		var arg interface{}
+		json.Unmarshal(param, &arg)
+		args = append(args, arg)
+	}
+	str := func(i int) string {
+		if i < len(args) {
+			if v, ok := args[i].(string); ok {
+				return v
+			}
+		}
+		return ""
+	}
+
+	switch method {
+	case "eth_blockNumber":
+		return hex(s.block), nil
+	case "eth_getBalance":
+		return utils.BigIntToHexString(*utils.DecimalToBigInt(s.ethBalances[strings.ToLower(str(0))].Shift(18))), nil
+	case "eth_getTransactionCount":
+		nonce := s.nonces[strings.ToLower(str(0))]
+		if str(1) == "pending" {
+			nonce = s.pendingNonce(str(0))
+		}
+		return hex(int64(nonce)), nil
+	case "eth_call":
+		var call struct {
+			To   string `json:"to"`
+			From string `json:"from"`
+			Data string `json:"data"`
+		}
+		if len(params) > 0 {
+			json.Unmarshal(params[0], &call)
+		}
+		return s.call(call.From, call.To, utils.HexString2Bytes(call.Data), str(1))
+	case "eth_sendRawTransaction":
+		return s.sendRawTransaction(str(0))
+	case "eth_getTransactionByHash":
+		if tx, ok := s.txs[strings.ToLower(str(0))]; ok {
+			return s.transactionJSON(tx), nil
+		}
+		return nil, nil
+	case "eth_getTransactionReceipt":
+		if tx, ok := s.txs[strings.ToLower(str(0))]; ok && tx.blockNumber > 0 {
+			return s.receiptJSON(tx), nil
+		}
+		return nil, nil
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock string     `json:"fromBlock"`
+			ToBlock   string     `json:"toBlock"`
+			Topics    [][]string `json:"topics"`
+		}
+		if len(params) > 0 {
+			json.Unmarshal(params[0], &filter)
+		}
+		return s.getLogs(filter.FromBlock, filter.ToBlock, filter.Topics)
+	case "eth_getBlockByNumber":
+		number, err := s.blockNumber(str(0))
+		if err != nil {
+			return nil, err
+		}
+		var full bool
+		if len(args) > 1 {
+			full, _ = args[1].(bool)
+		}
+		return s.blockJSON(number, full), nil
+	}
+	return nil, fmt.Errorf("the method %s does not exist/is not available", method)
+}
+
+func hex(i int64) string {
+	return fmt.Sprintf("0x%x", i)
+}
+
+func word(i *big.Int) string {
+	return fmt.Sprintf("%064x", i)
+}
+
+func addressWord(address string) string {
+	return fmt.Sprintf("%064s", strings.TrimPrefix(strings.ToLower(address), "0x"))
+}
+
+func boolWord(b bool) string {
+	if b {
+		return word(big.NewInt(1))
+	}
+	return word(big.NewInt(0))
+}
+
+// latest, pending or a block number
+func (s *Simulator) blockNumber(tag string) (number int64, err error) {
+	if tag == "" || tag == "latest" || tag == "pending" {
+		return s.block, nil
+	}
+	n, err := utils.HexString2Int(tag)
+	if err != nil {
+		return
+	}
+	if int64(n) > s.block {
+		return 0, errors.New("header not found")
+	}
+	return int64(n), nil
+}
+
+func (s *Simulator) pendingNonce(account string) uint64 {
+	nonce := s.nonces[strings.ToLower(account)]
+	for _, tx := range s.pending {
+		if tx.from == strings.ToLower(account) {
+			nonce++
+		}
+	}
+	return nonce
+}
+
+func (s *Simulator) call(from string, to string, data []byte, tag string) (result string, err error) {
+	if !utils.IsAddressEqual(to, HydroAddress) || len(data) < 4 {
+		return "0x", nil
+	}
+	method, err := hydroAbi.MethodById(data[:4])
+	if err != nil {
+		return "", errReverted
+	}
+	args, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return "", errReverted
+	}
+	number, err := s.blockNumber(tag)
+	if err != nil {
+		return
+	}
+	auctions := map[int64]Auction{}
+	if number == s.block {
+		for id, auction := range s.auctions {
+			auctions[id] = *auction
+		}
+	} else if auctions = s.states[number]; auctions == nil {
+		return "", fmt.Errorf("missing trie node of block %d", number)
+	}
+
+	switch method.Name {
+	case "getCurrentAuctions":
+		var ids []int64
+		for id := int64(1); id <= int64(len(auctions)); id++ {
+			if !auctions[id].Finished {
+				ids = append(ids, id)
+			}
+		}
+		result = word(big.NewInt(32)) + word(big.NewInt(int64(len(ids))))
+		for _, id := range ids {
+			result += word(big.NewInt(id))
+		}
+	case "getAuctionDetails":
+		auction, ok := auctions[int64(args[0].(uint32))]
+		if !ok {
+			return "", errReverted
+		}
+		var price = decimal.Zero
+		if auction.LeftCollateral.IsPositive() {
+			price = auction.LeftDebt.Div(auction.LeftCollateral)
+		}
+		result = addressWord(auction.Borrower) +
+			word(big.NewInt(0)) +
+			addressWord(auction.Debt.Address) +
+			addressWord(auction.Collateral.Address) +
+			word(toRaw(auction.LeftDebt, auction.Debt)) +
+			word(toRaw(auction.LeftCollateral, auction.Collateral)) +
+			word(utils.DecimalToBigInt(auction.Ratio.Shift(18))) +
+			word(utils.DecimalToBigInt(price.Shift(18))) +
+			boolWord(auction.Finished)
+	case "balanceOf":
+		asset, user := args[0].(common.Address), args[1].(common.Address)
+		result = word(big.NewInt(0))
+		for _, a := range s.assets {
+			if utils.IsAddressEqual(a.Address, asset.Hex()) {
+				result = word(toRaw(s.balance(user.Hex(), a.Symbol), a))
+			}
+		}
+	case "canMatchOrdersFrom":
+		owner := args[0].(common.Address)
+		result = boolWord(s.delegates[strings.ToLower(owner.Hex())][strings.ToLower(from)])
+	default:
+		return "", errReverted
+	}
+	return "0x" + result, nil
+}
+
+func (s *Simulator) sendRawTransaction(rawTx string) (hash string, err error) {
+	tx := new(types.Transaction)
+	if err = rlp.DecodeBytes(utils.HexString2Bytes(rawTx), tx); err != nil {
+		return
+	}
+	sender, err := types.Sender(types.NewEIP155Signer(big.NewInt(ChainID)), tx)
+	if err != nil {
+		return
+	}
+	hash = strings.ToLower(tx.Hash().Hex())
+	from := strings.ToLower(sender.Hex())
+	if _, ok := s.txs[hash]; ok {
+		return "", fmt.Errorf("known transaction: %s", hash[2:])
+	}
+	if nonce := s.pendingNonce(from); tx.Nonce() < nonce {
+		return "", errors.New("nonce too low")
+	} else if tx.Nonce() > nonce {
+		return "", errors.New("nonce too high")
+	}
+	maxGasCost := decimal.NewFromBigInt(new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())), -18)
+	if s.ethBalances[from].LessThan(maxGasCost) {
+		return "", errors.New("insufficient funds for gas * price + value")
+	}
+	if tx.To() == nil || !utils.IsAddressEqual(tx.To().Hex(), HydroAddress) {
+		return "", errors.New("only transactions to the hydro contract are simulated")
+	}
+
+	pending := &transaction{
+		hash:     hash,
+		from:     from,
+		nonce:    tx.Nonce(),
+		gasPrice: tx.GasPrice(),
+		input:    tx.Data(),
+	}
+	s.txs[hash] = pending
+	s.pending = append(s.pending, pending)
+	if s.AutoMine {
+		s.mine()
+	}
+	return
+}
+
+// run the transaction on the current state, a failed transaction still pays its gas
+func (s *Simulator) execute(tx *transaction) {
+	s.nonces[tx.from]++
+	s.ethBalances[tx.from] = s.ethBalances[tx.from].Sub(decimal.NewFromBigInt(new(big.Int).Mul(tx.gasPrice, big.NewInt(fillGasUsed)), -18))
+
+	var err = errReverted
+	if method, methodErr := hydroAbi.MethodById(tx.input[:4]); methodErr == nil {
+		args, unpackErr := method.Inputs.UnpackValues(tx.input[4:])
+		if unpackErr == nil {
+			switch method.Name {
+			case "fillAuctionWithAmount":
+				err = s.fillAuction(tx, int64(args[0].(uint32)), args[1].(*big.Int))
+			case "approveDelegate", "revokeDelegate":
+				delegate := strings.ToLower(args[0].(common.Address).Hex())
+				if s.delegates[tx.from] == nil {
+					s.delegates[tx.from] = map[string]bool{}
+				}
+				s.delegates[tx.from][delegate] = method.Name == "approveDelegate"
+				err = nil
+			}
+		}
+	}
+	tx.reverted = err != nil
+}
+
+// repay up to amount of the debt left. With a ratio below 1 the bidder gets that share of the collateral
+// the debt pays for, the rest goes back to the borrower. Above 1 the bidder gets all of it but repays less.
+func (s *Simulator) fillAuction(tx *transaction, id int64, amount *big.Int) error {
+	auction, ok := s.auctions[id]
+	if !ok || auction.Finished {
+		return errReverted
+	}
+	repay := decimal.Min(fromRaw(amount, auction.Debt), auction.LeftDebt)
+	collateral := repay.Mul(auction.LeftCollateral).Div(auction.LeftDebt).Truncate(auction.Collateral.Decimals)
+	bidderRepay, bidderCollateral := repay, collateral
+	if auction.Ratio.LessThan(decimal.New(1, 0)) {
+		bidderCollateral = collateral.Mul(auction.Ratio).Truncate(auction.Collateral.Decimals)
+	} else {
+		bidderRepay = repay.Div(auction.Ratio).Truncate(auction.Debt.Decimals)
+	}
+	if !repay.IsPositive() || s.balance(tx.from, auction.Debt.Symbol).LessThan(bidderRepay) {
+		return errReverted
+	}
+
+	s.setBalance(tx.from, auction.Debt.Symbol, s.balance(tx.from, auction.Debt.Symbol).Sub(bidderRepay))
+	s.setBalance(tx.from, auction.Collateral.Symbol, s.balance(tx.from, auction.Collateral.Symbol).Add(bidderCollateral))
+	auction.LeftDebt = auction.LeftDebt.Sub(repay)
+	auction.LeftCollateral = auction.LeftCollateral.Sub(collateral)
+	auction.Finished = !auction.LeftDebt.IsPositive()
+
+	tx.logs = append(tx.logs, &log{
+		topics: []string{hydroAbi.Events["FillAuction"].ID().Hex(), "0x" + word(big.NewInt(id))},
+		data: "0x" + addressWord(tx.from) +
+			word(toRaw(repay, auction.Debt)) +
+			word(toRaw(bidderRepay, auction.Debt)) +
+			word(toRaw(bidderCollateral, auction.Collateral)) +
+			word(toRaw(auction.LeftDebt, auction.Debt)),
+	})
+	return nil
+}
+
+func blockHash(number int64) string {
+	return fmt.Sprintf("0x%064x", number)
+}
+
+func (s *Simulator) transactionJSON(tx *transaction) map[string]interface{} {
+	result := map[string]interface{}{
+		"hash":             tx.hash,
+		"nonce":            hex(int64(tx.nonce)),
+		"from":             tx.from,
+		"to":               HydroAddress,
+		"value":            "0x0",
+		"gas":              hex(500000),
+		"gasPrice":         utils.BigIntToHexString(*tx.gasPrice),
+		"input":            utils.Bytes2HexString(tx.input),
+		"blockHash":        nil,
+		"blockNumber":      nil,
+		"transactionIndex": nil,
+	}
+	if tx.blockNumber > 0 {
+		result["blockHash"] = blockHash(tx.blockNumber)
+		result["blockNumber"] = hex(tx.blockNumber)
+		result["transactionIndex"] = hex(int64(tx.index))
+	}
+	return result
+}
+
+func (s *Simulator) logJSON(tx *transaction, l *log) map[string]interface{} {
+	return map[string]interface{}{
+		"removed":          false,
+		"logIndex":         hex(int64(l.index)),
+		"transactionIndex": hex(int64(tx.index)),
+		"transactionHash":  tx.hash,
+		"blockNumber":      hex(tx.blockNumber),
+		"blockHash":        blockHash(tx.blockNumber),
+		"address":          HydroAddress,
+		"data":             l.data,
+		"topics":           l.topics,
+	}
+}
+
+func (s *Simulator) receiptJSON(tx *transaction) map[string]interface{} {
+	status := "0x1"
+	if tx.reverted {
+		status = "0x0"
+	}
+	logs := []interface{}{}
+	for _, l := range tx.logs {
+		logs = append(logs, s.logJSON(tx, l))
+	}
+	return map[string]interface{}{
+		"transactionHash":   tx.hash,
+		"transactionIndex":  hex(int64(tx.index)),
+		"blockHash":         blockHash(tx.blockNumber),
+		"blockNumber":       hex(tx.blockNumber),
+		"cumulativeGasUsed": hex(int64(fillGasUsed * (tx.index + 1))),
+		"gasUsed":           hex(fillGasUsed),
+		"logs":              logs,
+		"logsBloom":         "0x",
+		"root":              "",
+		"status":            status,
+	}
+}
+
+func (s *Simulator) blockJSON(number int64, full bool) map[string]interface{} {
+	transactions := []interface{}{}
+	for _, tx := range s.blocks[number] {
+		if full {
+			transactions = append(transactions, s.transactionJSON(tx))
+		} else {
+			transactions = append(transactions, tx.hash)
+		}
+	}
+	return map[string]interface{}{
+		"number":       hex(number),
+		"hash":         blockHash(number),
+		"parentHash":   blockHash(number - 1),
+		"timestamp":    hex(s.blockTime(number)),
+		"transactions": transactions,
+		"uncles":       []string{},
+	}
+}
+
+func (s *Simulator) getLogs(fromTag string, toTag string, topics [][]string) (logs []interface{}, err error) {
+	from, err := s.blockNumber(fromTag)
+	if err != nil {
+		return
+	}
+	to, err := s.blockNumber(toTag)
+	if err != nil {
+		return
+	}
+	logs = []interface{}{}
+	for number := from; number <= to; number++ {
+		for _, tx := range s.blocks[number] {
+			for _, l := range tx.logs {
+				if matchTopics(l.topics, topics) {
+					logs = append(logs, s.logJSON(tx, l))
+				}
+			}
+		}
+	}
+	return
+}
+
+// every position of the filter matches if it is empty or has the topic
+func matchTopics(topics []string, filter [][]string) bool {
+	for i, options := range filter {
+		if len(options) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		var ok bool
+		for _, option := range options {
+			ok = ok || strings.EqualFold(option, topics[i])
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
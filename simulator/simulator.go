@@ -0,0 +1,311 @@
+// Package simulator is an in-process Hydro node and DDEX api for tests. Once activated, the http requests of the
+// clients are served by the simulator, so the bot runs unchanged against a simulated chain and exchange.
+package simulator
+
+import (
+	"auctionBidder/utils"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	NodeUrl      = "http://node.simulator"
+	DdexUrl      = "http://ddex.simulator/v4"
+	HydroAddress = "0x00000000000000000000000000000000000d0d0e"
+	ChainID      = 1337
+
+	fillGasUsed = 150000
+	blockTime   = 15 // seconds
+	genesisTime = 1577836800
+)
+
+type Asset struct {
+	Symbol   string
+	Address  string
+	Decimals int32
+	USDPrice decimal.Decimal // oracle price served by ddex
+}
+
+// Level is the amount of all orders at a price.
+type Level struct {
+	Price  decimal.Decimal
+	Amount decimal.Decimal
+}
+
+type Market struct {
+	Base         *Asset
+	Quote        *Asset
+	TakerFeeRate decimal.Decimal
+	Bids         []*Level // best first
+	Asks         []*Level // best first
+}
+
+func (m *Market) TradingPair() string {
+	return m.Base.Symbol + "-" + m.Quote.Symbol
+}
+
+type Auction struct {
+	ID             int64
+	Borrower       string
+	Debt           *Asset
+	Collateral     *Asset
+	LeftDebt       decimal.Decimal
+	LeftCollateral decimal.Decimal
+	Ratio          decimal.Decimal // share of the collateral given to bidders, above 1 the debt to repay is divided by it instead
+	Finished       bool
+}
+
+// Simulator holds the state of the chain and of the exchange, it is safe for concurrent use.
+type Simulator struct {
+	AutoMine bool // mine every transaction as soon as it is sent, otherwise they wait for Mine
+
+	mu          sync.Mutex
+	block       int64
+	assets      map[string]*Asset                     // symbol -> asset
+	markets     map[string]*Market                    // trading pair -> market
+	balances    map[string]map[string]decimal.Decimal // account -> symbol -> hydro balance
+	ethBalances map[string]decimal.Decimal
+	nonces      map[string]uint64          // account -> transactions mined
+	delegates   map[string]map[string]bool // account -> approved delegates
+	auctions    map[int64]*Auction
+	states      map[int64]map[int64]Auction // auctions as they were at the end of past blocks
+	pending     []*transaction
+	txs         map[string]*transaction // hash -> transaction
+	blocks      map[int64][]*transaction
+	orders      map[string]*Order
+	orderIDs    []string // in creation order
+	env         map[string]string
+
+	nodeRequests int // http requests to the node, a batch is one
+}
+
+func New() *Simulator {
+	return &Simulator{
+		AutoMine:    true,
+		block:       1,
+		assets:      map[string]*Asset{},
+		markets:     map[string]*Market{},
+		balances:    map[string]map[string]decimal.Decimal{},
+		ethBalances: map[string]decimal.Decimal{},
+		nonces:      map[string]uint64{},
+		delegates:   map[string]map[string]bool{},
+		auctions:    map[int64]*Auction{},
+		states:      map[int64]map[int64]Auction{},
+		txs:         map[string]*transaction{},
+		blocks:      map[int64][]*transaction{},
+		orders:      map[string]*Order{},
+		env:         map[string]string{},
+	}
+}
+
+// Activate routes the requests of http.DefaultClient to the simulator and points the clients at it with the
+// environment variables they read. Requests to other hosts fail, so the ether gas station falls back to its default.
+func (s *Simulator) Activate() {
+	for key, value := range map[string]string{
+		"ETHEREUM_NODE_URL":      NodeUrl,
+		"DDEX_URL":               DdexUrl,
+		"HYDRO_CONTRACT_ADDRESS": HydroAddress,
+		"CHAIN_ID":               fmt.Sprintf("%d", ChainID),
+	} {
+		s.env[key] = os.Getenv(key)
+		os.Setenv(key, value)
+	}
+
+	node, ddex := http.HandlerFunc(s.serveNode), http.StripPrefix("/v4", http.HandlerFunc(s.serveDdex))
+	httpmock.Activate()
+	httpmock.RegisterNoResponder(func(req *http.Request) (*http.Response, error) {
+		var handler http.Handler
+		switch "http://" + req.URL.Host {
+		case NodeUrl:
+			handler = node
+		case strings.TrimSuffix(DdexUrl, "/v4"):
+			handler = ddex
+		default:
+			return nil, fmt.Errorf("no route to %s in the simulator", req.URL.Host)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Result(), nil
+	})
+}
+
+// Deactivate restores the http transport and the environment.
+func (s *Simulator) Deactivate() {
+	httpmock.DeactivateAndReset()
+	for key, value := range s.env {
+		os.Setenv(key, value)
+	}
+}
+
+// AddAsset lists an asset on ddex, ETH has the ether token address and the others an address derived from the symbol.
+func (s *Simulator) AddAsset(symbol string, decimals int32, usdPrice decimal.Decimal) *Asset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	address := utils.ETHERTOKENADDRESS
+	if symbol != "ETH" {
+		address = fmt.Sprintf("0x%x", utils.Keccak256([]byte(symbol))[12:])
+	}
+	asset := &Asset{symbol, address, decimals, usdPrice}
+	s.assets[symbol] = asset
+	return asset
+}
+
+func (s *Simulator) SetUSDPrice(symbol string, price decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assets[symbol].USDPrice = price
+}
+
+func (s *Simulator) AddMarket(base string, quote string, takerFeeRate decimal.Decimal) *Market {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	market := &Market{Base: s.assets[base], Quote: s.assets[quote], TakerFeeRate: takerFeeRate}
+	s.markets[market.TradingPair()] = market
+	return market
+}
+
+// SetOrderbook replaces the orders of the market, market orders take from it.
+func (s *Simulator) SetOrderbook(tradingPair string, bids []*Level, asks []*Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markets[tradingPair].Bids = copyLevels(bids)
+	s.markets[tradingPair].Asks = copyLevels(asks)
+}
+
+func copyLevels(levels []*Level) (copied []*Level) {
+	for _, level := range levels {
+		copied = append(copied, &Level{level.Price, level.Amount})
+	}
+	return
+}
+
+// SetBalance sets the balance of the account in the hydro contract.
+func (s *Simulator) SetBalance(account string, symbol string, amount decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setBalance(account, symbol, amount)
+}
+
+func (s *Simulator) Balance(account string, symbol string) decimal.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance(account, symbol)
+}
+
+func (s *Simulator) balance(account string, symbol string) decimal.Decimal {
+	return s.balances[strings.ToLower(account)][symbol]
+}
+
+func (s *Simulator) setBalance(account string, symbol string, amount decimal.Decimal) {
+	account = strings.ToLower(account)
+	if s.balances[account] == nil {
+		s.balances[account] = map[string]decimal.Decimal{}
+	}
+	s.balances[account][symbol] = amount
+}
+
+// SetEthBalance sets the ether of the account, transactions are rejected if it can't pay their gas.
+func (s *Simulator) SetEthBalance(account string, amount decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ethBalances[strings.ToLower(account)] = amount
+}
+
+func (s *Simulator) EthBalance(account string) decimal.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ethBalances[strings.ToLower(account)]
+}
+
+// AddAuction starts an auction of collateral for debt, bidders get ratio of the collateral.
+func (s *Simulator) AddAuction(debtSymbol string, collateralSymbol string, debt decimal.Decimal, collateral decimal.Decimal, ratio decimal.Decimal) (id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id = int64(len(s.auctions) + 1)
+	s.auctions[id] = &Auction{
+		ID:             id,
+		Borrower:       fmt.Sprintf("0x%040x", id),
+		Debt:           s.assets[debtSymbol],
+		Collateral:     s.assets[collateralSymbol],
+		LeftDebt:       debt,
+		LeftCollateral: collateral,
+		Ratio:          ratio,
+	}
+	return
+}
+
+// SetAuctionRatio changes the share of the collateral given to bidders, it grows every block on chain.
+func (s *Simulator) SetAuctionRatio(id int64, ratio decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auctions[id].Ratio = ratio
+}
+
+// Auction returns a copy of the current state of the auction.
+func (s *Simulator) Auction(id int64) Auction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.auctions[id]
+}
+
+func (s *Simulator) BlockNumber() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.block
+}
+
+// NodeRequests counts the http requests served by the node, a batch of json-rpc requests counts once.
+func (s *Simulator) NodeRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nodeRequests
+}
+
+// Mine mines the pending transactions in a new block, or an empty block if there are none.
+func (s *Simulator) Mine() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mine()
+	return s.block
+}
+
+func (s *Simulator) mine() {
+	state := map[int64]Auction{}
+	for id, auction := range s.auctions {
+		state[id] = *auction
+	}
+	s.states[s.block] = state
+	s.block++
+
+	var logIndex int
+	for i, tx := range s.pending {
+		tx.blockNumber, tx.index = s.block, i
+		s.execute(tx)
+		for _, log := range tx.logs {
+			log.index = logIndex
+			logIndex++
+		}
+	}
+	s.blocks[s.block] = s.pending
+	s.pending = nil
+}
+
+func (s *Simulator) blockTime(number int64) int64 {
+	return genesisTime + number*blockTime
+}
+
+// raw amount of an asset, as in the contract
+func toRaw(amount decimal.Decimal, asset *Asset) *big.Int {
+	return utils.DecimalToBigInt(amount.Mul(decimal.New(1, asset.Decimals)).Floor())
+}
+
+func fromRaw(raw *big.Int, asset *Asset) decimal.Decimal {
+	return decimal.NewFromBigInt(raw, -asset.Decimals)
+}
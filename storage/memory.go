@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"auctionBidder/utils"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sync"
+)
+
+// MemoryStore keeps the ledger in memory, for tests and dry runs.
+type MemoryStore struct {
+	lock            sync.Mutex
+	configSnapshots []string
+	Bids            []*Bid
+	Fills           []*Fill
+	HedgeOrders     []*HedgeOrder
+	Intents         []*Intent
+	Decisions       []*Decision
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) InsertConfigSnapshot(config string) (id int64, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.configSnapshots) == 0 || s.configSnapshots[len(s.configSnapshots)-1] != config {
+		s.configSnapshots = append(s.configSnapshots, config)
+	}
+	return int64(len(s.configSnapshots)), nil
+}
+
+func (s *MemoryStore) InsertBid(bid *Bid) (id int64, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if bid.TxHash != "" {
+		for _, existing := range s.Bids {
+			if existing.TxHash == bid.TxHash {
+				return 0, fmt.Errorf("duplicate bid %s", bid.TxHash)
+			}
+		}
+	}
+	record := *bid
+	record.ID = int64(len(s.Bids) + 1)
+	record.CreatedAt = utils.MillisecondTimestamp()
+	s.Bids = append(s.Bids, &record)
+	return record.ID, nil
+}
+
+func (s *MemoryStore) InsertFill(fill *Fill, status string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	bid, err := s.bid(fill.BidID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range s.Fills {
+		if existing.BidID == fill.BidID {
+			return fmt.Errorf("bid %d is already filled", fill.BidID)
+		}
+	}
+	record := *fill
+	record.CreatedAt = utils.MillisecondTimestamp()
+	s.Fills = append(s.Fills, &record)
+	bid.Status = status
+	return nil
+}
+
+func (s *MemoryStore) InsertHedgeOrder(order *HedgeOrder) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.bid(order.BidID); err != nil {
+		return err
+	}
+	record := *order
+	record.CreatedAt = utils.MillisecondTimestamp()
+	s.HedgeOrders = append(s.HedgeOrders, &record)
+	return nil
+}
+
+func (s *MemoryStore) QueryPosition() (map[string]decimal.Decimal, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	p := position{"ETH": decimal.Zero}
+	for _, fill := range s.Fills {
+		bid, _ := s.bid(fill.BidID)
+		p.addFill(bid, fill)
+	}
+	for _, order := range s.HedgeOrders {
+		bid, _ := s.bid(order.BidID)
+		p.addHedge(bid, order)
+	}
+	return p, nil
+}
+
+func (s *MemoryStore) QueryTrades(filter Filter) (trades []*Trade, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	byBid := map[int64]*Trade{}
+	for _, bid := range s.Bids {
+		if filter.match(bid.CreatedAt, bid.Market) {
+			record := *bid
+			trade := &Trade{Bid: &record}
+			byBid[bid.ID] = trade
+			trades = append(trades, trade)
+		}
+	}
+	for _, fill := range s.Fills {
+		if trade, ok := byBid[fill.BidID]; ok {
+			record := *fill
+			trade.Fill = &record
+		}
+	}
+	for _, order := range s.HedgeOrders {
+		if trade, ok := byBid[order.BidID]; ok {
+			record := *order
+			trade.HedgeOrders = append(trade.HedgeOrders, &record)
+		}
+	}
+	return
+}
+
+func (s *MemoryStore) InsertIntent(intent *Intent) (id int64, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record := *intent
+	record.ID = int64(len(s.Intents) + 1)
+	record.CreatedAt = utils.MillisecondTimestamp()
+	record.UpdatedAt = record.CreatedAt
+	s.Intents = append(s.Intents, &record)
+	return record.ID, nil
+}
+
+func (s *MemoryStore) UpdateIntent(intent *Intent) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if intent.ID < 1 || intent.ID > int64(len(s.Intents)) {
+		return fmt.Errorf("intent %d not found", intent.ID)
+	}
+	record := s.Intents[intent.ID-1]
+	record.UpdatedAt = utils.MillisecondTimestamp()
+	record.BidID, record.HedgeOrderId, record.State, record.Error = intent.BidID, intent.HedgeOrderId, intent.State, intent.Error
+	return nil
+}
+
+func (s *MemoryStore) QueryOpenIntents() (intents []*Intent, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, intent := range s.Intents {
+		if intent.State != INTENTRECORDED && intent.State != INTENTFAILED {
+			record := *intent
+			intents = append(intents, &record)
+		}
+	}
+	return
+}
+
+func (s *MemoryStore) InsertDecision(decision *Decision) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record := *decision
+	record.ID = int64(len(s.Decisions) + 1)
+	record.CreatedAt = utils.MillisecondTimestamp()
+	s.Decisions = append(s.Decisions, &record)
+	return nil
+}
+
+func (s *MemoryStore) QueryDecisions(filter Filter) (decisions []*Decision, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, decision := range s.Decisions {
+		if filter.match(decision.CreatedAt, decision.Market) {
+			record := *decision
+			decisions = append(decisions, &record)
+		}
+	}
+	return
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) bid(id int64) (*Bid, error) {
+	if id < 1 || id > int64(len(s.Bids)) {
+		return nil, fmt.Errorf("bid %d not found", id)
+	}
+	return s.Bids[id-1], nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+// set POSTGRES_TEST_URL, e.g. postgres://postgres@localhost/postgres?sslmode=disable, to run against a local postgres
+func TestPostgresStore(t *testing.T) {
+	testUrl := os.Getenv("POSTGRES_TEST_URL")
+	if testUrl == "" {
+		t.Skip("POSTGRES_TEST_URL is not set")
+	}
+
+	// a schema of its own, dropped after the test
+	db, err := sql.Open("postgres", testUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schema := fmt.Sprintf("auction_bidder_test_%d", time.Now().UnixNano())
+	if _, err = db.Exec("create schema " + schema); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("drop schema " + schema + " cascade")
+
+	u, _ := url.Parse(testUrl)
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	for i := 0; i < 2; i++ {
+		store, err := NewPostgresStore(u.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+	}
+	store, err := NewPostgresStore(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testStore(t, store)
+}
@@ -0,0 +1,524 @@
+package storage
+
+import (
+	"auctionBidder/utils"
+	"database/sql"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// SqlStore keeps the ledger in a sql database, sqlite or postgres.
+type SqlStore struct {
+	db               *sql.DB
+	postgres         bool // postgres doesn't support LastInsertId and has its own placeholders
+	latestConfig     *sql.Stmt
+	insertConfig     *sql.Stmt
+	insertBid        *sql.Stmt
+	insertFill       *sql.Stmt
+	updateBidStatus  *sql.Stmt
+	insertHedgeOrder *sql.Stmt
+	insertIntent     *sql.Stmt
+	updateIntent     *sql.Stmt
+	insertDecision   *sql.Stmt
+}
+
+func newSqlStore(db *sql.DB, postgres bool) (store *SqlStore, err error) {
+	store = &SqlStore{db: db, postgres: postgres}
+	for _, stmt := range []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&store.latestConfig, "select id, config from configSnapshots order by id desc limit 1"},
+		{&store.insertConfig, "insert into configSnapshots(createdAt, config) values(?, ?)"},
+		{&store.insertBid, `insert into bids(createdAt, configSnapshotId, account, auctionId, market, debtSymbol, collateralSymbol, blockNumber,
+	availableDebt, availableCollateral, auctionPrice, debtPriceUSD, collateralPriceUSD, ethPriceUSD, expectedReceiveDebt,
+	repayDebt, gasPriceGwei, txHash, status, error) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+		{&store.insertFill, "insert into fills(bidId, createdAt, blockNumber, repayDebt, receiveCollateral, gasUsed, gasCost) values(?, ?, ?, ?, ?, ?, ?)"},
+		{&store.updateBidStatus, "update bids set status = ? where id = ?"},
+		{&store.insertHedgeOrder, `insert into hedgeOrders(bidId, createdAt, market, side, ddexOrderId, amount, priceLimit, sellAmount, receiveAmount,
+	avgPrice, feeRate, gasFee, status) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+		{&store.insertIntent, `insert into intents(createdAt, updatedAt, account, auctionId, repayDebt, gasPriceGwei, nonce, txHash, rawTx,
+	bidId, hedgeOrderId, state, error) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+		{&store.updateIntent, "update intents set updatedAt = ?, bidId = ?, hedgeOrderId = ?, state = ?, error = ? where id = ?"},
+		{&store.insertDecision, `insert into decisions(createdAt, configSnapshotId, blockNumber, auctionId, market, debtSymbol, collateralSymbol, auctionPrice, ratio,
+	availableDebt, availableCollateral, freeDebt, collateralPriceUSD, repayDebt, collateral, expectedReceiveDebt, minReceiveDebt,
+	profitMargin, gasPriceGwei, action, reason, error) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+	} {
+		query := stmt.query
+		if postgres {
+			query = rebind(query)
+			if stmt.stmt == &store.insertConfig || stmt.stmt == &store.insertBid || stmt.stmt == &store.insertIntent {
+				query += " returning id"
+			}
+		}
+		if *stmt.stmt, err = db.Prepare(query); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	return
+}
+
+// postgres placeholders are $1, $2...
+func rebind(query string) string {
+	var builder strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&builder, "$%d", n)
+		} else {
+			builder.WriteRune(c)
+		}
+	}
+	return builder.String()
+}
+
+// migrate applies the pending migrations, one transaction each. The lock statement runs first in every
+// transaction, so bots sharing a database don't apply the same migration twice.
+func migrate(db *sql.DB, migrations []string, lock string) (err error) {
+	if _, err = db.Exec("create table if not exists schemaVersion (version INTEGER not null)"); err != nil {
+		return
+	}
+
+	for {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		var version int
+		if lock != "" {
+			_, err = tx.Exec(lock)
+		}
+		if err == nil {
+			err = tx.QueryRow("select coalesce(max(version), 0) from schemaVersion").Scan(&version)
+		}
+		if err == nil && version > len(migrations) {
+			err = fmt.Errorf("database schema version %d is newer than supported version %d", version, len(migrations))
+		}
+		if err != nil || version == len(migrations) {
+			tx.Rollback()
+			return err
+		}
+
+		if _, err = tx.Exec(migrations[version]); err == nil {
+			if _, err = tx.Exec("delete from schemaVersion"); err == nil {
+				_, err = tx.Exec(fmt.Sprintf("insert into schemaVersion(version) values(%d)", version+1))
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate database to version %d failed: %s", version+1, err.Error())
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+		logrus.Infof("migrate database to version %d", version+1)
+	}
+}
+
+// zero means unknown
+func nullInt(value int64) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+func (s *SqlStore) insert(stmt *sql.Stmt, args ...interface{}) (id int64, err error) {
+	if s.postgres {
+		err = stmt.QueryRow(args...).Scan(&id)
+		return
+	}
+	res, err := stmt.Exec(args...)
+	if err != nil {
+		return
+	}
+	return res.LastInsertId()
+}
+
+func (s *SqlStore) InsertConfigSnapshot(config string) (id int64, err error) {
+	var latest string
+	err = s.latestConfig.QueryRow().Scan(&id, &latest)
+	if err == nil && latest == config {
+		return
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return
+	}
+
+	return s.insert(s.insertConfig, utils.MillisecondTimestamp(), config)
+}
+
+func (s *SqlStore) InsertBid(bid *Bid) (id int64, err error) {
+	var txHash interface{} // null unless sent, txHash is unique
+	if bid.TxHash != "" {
+		txHash = bid.TxHash
+	}
+	return s.insert(s.insertBid,
+		utils.MillisecondTimestamp(),
+		nullInt(bid.ConfigSnapshotID),
+		bid.Account,
+		bid.AuctionID,
+		bid.Market,
+		bid.DebtSymbol,
+		bid.CollateralSymbol,
+		bid.BlockNumber,
+		bid.AvailableDebt.String(),
+		bid.AvailableCollateral.String(),
+		bid.AuctionPrice.String(),
+		bid.DebtPriceUSD.String(),
+		bid.CollateralPriceUSD.String(),
+		bid.EthPriceUSD.String(),
+		bid.ExpectedReceiveDebt.String(),
+		bid.RepayDebt.String(),
+		bid.GasPriceGwei,
+		txHash,
+		bid.Status,
+		bid.Error,
+	)
+}
+
+func (s *SqlStore) InsertFill(fill *Fill, status string) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return
+	}
+	_, err = tx.Stmt(s.insertFill).Exec(
+		fill.BidID,
+		utils.MillisecondTimestamp(),
+		fill.BlockNumber,
+		fill.RepayDebt.String(),
+		fill.ReceiveCollateral.String(),
+		fill.GasUsed,
+		fill.GasCost.String(),
+	)
+	if err == nil {
+		var res sql.Result
+		if res, err = tx.Stmt(s.updateBidStatus).Exec(status, fill.BidID); err == nil {
+			if updated, _ := res.RowsAffected(); updated == 0 {
+				err = fmt.Errorf("bid %d not found", fill.BidID)
+			}
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	return tx.Commit()
+}
+
+func (s *SqlStore) InsertHedgeOrder(order *HedgeOrder) (err error) {
+	_, err = s.insertHedgeOrder.Exec(
+		order.BidID,
+		utils.MillisecondTimestamp(),
+		order.Market,
+		order.Side,
+		order.DdexOrderId,
+		order.Amount.String(),
+		order.PriceLimit.String(),
+		order.SellAmount.String(),
+		order.ReceiveAmount.String(),
+		order.AvgPrice.String(),
+		order.FeeRate.String(),
+		order.GasFee.String(),
+		order.Status,
+	)
+	return
+}
+
+func (s *SqlStore) QueryPosition() (map[string]decimal.Decimal, error) {
+	p := position{"ETH": decimal.Zero}
+
+	rows, err := s.db.Query("select b.debtSymbol, b.collateralSymbol, f.repayDebt, f.receiveCollateral, f.gasCost from fills f join bids b on f.bidId = b.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bid Bid
+		var repayDebt, receiveCollateral, gasCost string
+		if err = rows.Scan(&bid.DebtSymbol, &bid.CollateralSymbol, &repayDebt, &receiveCollateral, &gasCost); err != nil {
+			return nil, err
+		}
+		p.addFill(&bid, &Fill{
+			RepayDebt:         utils.String2Decimal(repayDebt),
+			ReceiveCollateral: utils.String2Decimal(receiveCollateral),
+			GasCost:           utils.String2Decimal(gasCost),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	hedgeRows, err := s.db.Query("select b.debtSymbol, b.collateralSymbol, h.sellAmount, h.receiveAmount from hedgeOrders h join bids b on h.bidId = b.id")
+	if err != nil {
+		return nil, err
+	}
+	defer hedgeRows.Close()
+	for hedgeRows.Next() {
+		var bid Bid
+		var sellAmount, receiveAmount string
+		if err = hedgeRows.Scan(&bid.DebtSymbol, &bid.CollateralSymbol, &sellAmount, &receiveAmount); err != nil {
+			return nil, err
+		}
+		p.addHedge(&bid, &HedgeOrder{
+			SellAmount:    utils.String2Decimal(sellAmount),
+			ReceiveAmount: utils.String2Decimal(receiveAmount),
+		})
+	}
+	return p, hedgeRows.Err()
+}
+
+// scans a nullable decimal column, null is zero
+type decimalColumn struct {
+	d *decimal.Decimal
+}
+
+func (c decimalColumn) Scan(value interface{}) (err error) {
+	switch v := value.(type) {
+	case nil:
+		*c.d = decimal.Zero
+	case []byte:
+		*c.d, err = decimal.NewFromString(string(v))
+	case string:
+		*c.d, err = decimal.NewFromString(v)
+	case float64:
+		*c.d = decimal.NewFromFloat(v)
+	case int64:
+		*c.d = decimal.New(v, 0)
+	default:
+		err = fmt.Errorf("can't scan %T into decimal", value)
+	}
+	return
+}
+
+func (s *SqlStore) query(query string, args ...interface{}) (*sql.Rows, error) {
+	if s.postgres {
+		query = rebind(query)
+	}
+	return s.db.Query(query, args...)
+}
+
+// the where clause of the filter on the createdAt and market columns of a table
+func (f *Filter) where(prefix string) (condition string, args []interface{}) {
+	var where []string
+	if !f.From.IsZero() {
+		where = append(where, prefix+"createdAt >= ?")
+		args = append(args, milliseconds(f.From))
+	}
+	if !f.To.IsZero() {
+		where = append(where, prefix+"createdAt < ?")
+		args = append(args, milliseconds(f.To))
+	}
+	if len(f.Markets) > 0 {
+		where = append(where, prefix+"market in (?"+strings.Repeat(", ?", len(f.Markets)-1)+")")
+		for _, market := range f.Markets {
+			args = append(args, market)
+		}
+	}
+	if len(where) > 0 {
+		condition = " where " + strings.Join(where, " and ")
+	}
+	return
+}
+
+func (s *SqlStore) QueryTrades(filter Filter) (trades []*Trade, err error) {
+	condition, args := filter.where("b.")
+
+	rows, err := s.query(`select b.id, b.createdAt, b.configSnapshotId, b.account, b.auctionId, b.market, b.debtSymbol, b.collateralSymbol,
+	b.blockNumber, b.availableDebt, b.availableCollateral, b.auctionPrice, b.debtPriceUSD, b.collateralPriceUSD, b.ethPriceUSD,
+	b.expectedReceiveDebt, b.repayDebt, b.gasPriceGwei, b.txHash, b.status, b.error from bids b`+condition+" order by b.id", args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	byBid := map[int64]*Trade{}
+	for rows.Next() {
+		var bid Bid
+		var createdAt, configSnapshotId, blockNumber, gasPriceGwei sql.NullInt64
+		var account, market, txHash sql.NullString
+		if err = rows.Scan(&bid.ID, &createdAt, &configSnapshotId, &account, &bid.AuctionID, &market, &bid.DebtSymbol, &bid.CollateralSymbol,
+			&blockNumber, decimalColumn{&bid.AvailableDebt}, decimalColumn{&bid.AvailableCollateral}, decimalColumn{&bid.AuctionPrice},
+			decimalColumn{&bid.DebtPriceUSD}, decimalColumn{&bid.CollateralPriceUSD}, decimalColumn{&bid.EthPriceUSD},
+			decimalColumn{&bid.ExpectedReceiveDebt}, decimalColumn{&bid.RepayDebt}, &gasPriceGwei, &txHash, &bid.Status, &bid.Error); err != nil {
+			return nil, err
+		}
+		bid.CreatedAt, bid.ConfigSnapshotID, bid.BlockNumber, bid.GasPriceGwei = createdAt.Int64, configSnapshotId.Int64, blockNumber.Int64, gasPriceGwei.Int64
+		bid.Account, bid.Market, bid.TxHash = account.String, market.String, txHash.String
+		trade := &Trade{Bid: &bid}
+		byBid[bid.ID] = trade
+		trades = append(trades, trade)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	fillRows, err := s.query(`select f.bidId, f.createdAt, f.blockNumber, f.repayDebt, f.receiveCollateral, f.gasUsed, f.gasCost
+	from fills f join bids b on f.bidId = b.id`+condition, args...)
+	if err != nil {
+		return
+	}
+	defer fillRows.Close()
+	for fillRows.Next() {
+		var fill Fill
+		var createdAt, blockNumber, gasUsed sql.NullInt64
+		if err = fillRows.Scan(&fill.BidID, &createdAt, &blockNumber, decimalColumn{&fill.RepayDebt}, decimalColumn{&fill.ReceiveCollateral},
+			&gasUsed, decimalColumn{&fill.GasCost}); err != nil {
+			return nil, err
+		}
+		fill.CreatedAt, fill.BlockNumber, fill.GasUsed = createdAt.Int64, blockNumber.Int64, gasUsed.Int64
+		if trade, ok := byBid[fill.BidID]; ok {
+			trade.Fill = &fill
+		}
+	}
+	if err = fillRows.Err(); err != nil {
+		return
+	}
+
+	hedgeRows, err := s.query(`select h.bidId, h.createdAt, h.market, h.side, h.ddexOrderId, h.amount, h.priceLimit, h.sellAmount,
+	h.receiveAmount, h.avgPrice, h.feeRate, h.gasFee, h.status from hedgeOrders h join bids b on h.bidId = b.id`+condition+" order by h.id", args...)
+	if err != nil {
+		return
+	}
+	defer hedgeRows.Close()
+	for hedgeRows.Next() {
+		var order HedgeOrder
+		var createdAt sql.NullInt64
+		var market, side sql.NullString
+		if err = hedgeRows.Scan(&order.BidID, &createdAt, &market, &side, &order.DdexOrderId, decimalColumn{&order.Amount},
+			decimalColumn{&order.PriceLimit}, decimalColumn{&order.SellAmount}, decimalColumn{&order.ReceiveAmount},
+			decimalColumn{&order.AvgPrice}, decimalColumn{&order.FeeRate}, decimalColumn{&order.GasFee}, &order.Status); err != nil {
+			return nil, err
+		}
+		order.CreatedAt, order.Market, order.Side = createdAt.Int64, market.String, side.String
+		if trade, ok := byBid[order.BidID]; ok {
+			trade.HedgeOrders = append(trade.HedgeOrders, &order)
+		}
+	}
+	return trades, hedgeRows.Err()
+}
+
+func (s *SqlStore) InsertIntent(intent *Intent) (id int64, err error) {
+	now := utils.MillisecondTimestamp()
+	return s.insert(s.insertIntent,
+		now,
+		now,
+		intent.Account,
+		intent.AuctionID,
+		intent.RepayDebt.String(),
+		intent.GasPriceGwei,
+		intent.Nonce,
+		intent.TxHash,
+		intent.RawTx,
+		nullInt(intent.BidID),
+		intent.HedgeOrderId,
+		intent.State,
+		intent.Error,
+	)
+}
+
+func (s *SqlStore) UpdateIntent(intent *Intent) (err error) {
+	res, err := s.updateIntent.Exec(
+		utils.MillisecondTimestamp(),
+		nullInt(intent.BidID),
+		intent.HedgeOrderId,
+		intent.State,
+		intent.Error,
+		intent.ID,
+	)
+	if err != nil {
+		return
+	}
+	if updated, _ := res.RowsAffected(); updated == 0 {
+		err = fmt.Errorf("intent %d not found", intent.ID)
+	}
+	return
+}
+
+func (s *SqlStore) QueryOpenIntents() (intents []*Intent, err error) {
+	rows, err := s.query(`select id, createdAt, updatedAt, account, auctionId, repayDebt, gasPriceGwei, nonce, txHash, rawTx, bidId,
+	hedgeOrderId, state, error from intents where state not in (?, ?) order by id`, INTENTRECORDED, INTENTFAILED)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var intent Intent
+		var bidId sql.NullInt64
+		if err = rows.Scan(&intent.ID, &intent.CreatedAt, &intent.UpdatedAt, &intent.Account, &intent.AuctionID, decimalColumn{&intent.RepayDebt},
+			&intent.GasPriceGwei, &intent.Nonce, &intent.TxHash, &intent.RawTx, &bidId, &intent.HedgeOrderId, &intent.State, &intent.Error); err != nil {
+			return nil, err
+		}
+		intent.BidID = bidId.Int64
+		intents = append(intents, &intent)
+	}
+	return intents, rows.Err()
+}
+
+func (s *SqlStore) InsertDecision(decision *Decision) (err error) {
+	decision.CreatedAt = utils.MillisecondTimestamp()
+	_, err = s.insertDecision.Exec(
+		decision.CreatedAt,
+		nullInt(decision.ConfigSnapshotID),
+		decision.BlockNumber,
+		decision.AuctionID,
+		decision.Market,
+		decision.DebtSymbol,
+		decision.CollateralSymbol,
+		decision.AuctionPrice.String(),
+		decision.Ratio.String(),
+		decision.AvailableDebt.String(),
+		decision.AvailableCollateral.String(),
+		decision.FreeDebt.String(),
+		decision.CollateralPriceUSD.String(),
+		decision.RepayDebt.String(),
+		decision.Collateral.String(),
+		decision.ExpectedReceiveDebt.String(),
+		decision.MinReceiveDebt.String(),
+		decision.ProfitMargin.String(),
+		decision.GasPriceGwei,
+		decision.Action,
+		decision.Reason,
+		decision.Error,
+	)
+	return
+}
+
+func (s *SqlStore) QueryDecisions(filter Filter) (decisions []*Decision, err error) {
+	condition, args := filter.where("")
+
+	rows, err := s.query(`select id, createdAt, configSnapshotId, blockNumber, auctionId, market, debtSymbol, collateralSymbol, auctionPrice, ratio,
+	availableDebt, availableCollateral, freeDebt, collateralPriceUSD, repayDebt, collateral, expectedReceiveDebt, minReceiveDebt,
+	profitMargin, gasPriceGwei, action, reason, error from decisions`+condition+" order by id", args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d Decision
+		var configSnapshotId sql.NullInt64
+		if err = rows.Scan(&d.ID, &d.CreatedAt, &configSnapshotId, &d.BlockNumber, &d.AuctionID, &d.Market, &d.DebtSymbol, &d.CollateralSymbol,
+			decimalColumn{&d.AuctionPrice}, decimalColumn{&d.Ratio}, decimalColumn{&d.AvailableDebt}, decimalColumn{&d.AvailableCollateral},
+			decimalColumn{&d.FreeDebt}, decimalColumn{&d.CollateralPriceUSD}, decimalColumn{&d.RepayDebt}, decimalColumn{&d.Collateral},
+			decimalColumn{&d.ExpectedReceiveDebt}, decimalColumn{&d.MinReceiveDebt}, decimalColumn{&d.ProfitMargin}, &d.GasPriceGwei,
+			&d.Action, &d.Reason, &d.Error); err != nil {
+			return nil, err
+		}
+		d.ConfigSnapshotID = configSnapshotId.Int64
+		decisions = append(decisions, &d)
+	}
+	return decisions, rows.Err()
+}
+
+func (s *SqlStore) Close() error {
+	for _, stmt := range []*sql.Stmt{s.latestConfig, s.insertConfig, s.insertBid, s.insertFill, s.updateBidStatus, s.insertHedgeOrder,
+		s.insertIntent, s.updateIntent, s.insertDecision} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+	return s.db.Close()
+}
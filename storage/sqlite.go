@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations[i] upgrades the schema from version i to i+1. Never edit a released migration, append a new one.
+var sqliteMigrations = []string{
+	// 1: the original table, databases created before versioning already have it
+	`create table if not exists auctions (
+	txHash TEXT not null primary key,
+	auctionId INTEGER not null,
+	debtSymbol TEXT not null,
+	collateralSymbol TEXT not null,
+	repayDebt TEXT not null,
+	receiveCollateral TEXT not null,
+	ddexOrderId TEXT not null,
+	ddexSellCollateral TEXT not null,
+	ddexReceiveDebt TEXT not null,
+	gasCost TEXT not null
+	);`,
+
+	// 2: trade ledger. Amounts are decimal strings, times are unix milliseconds.
+	// Columns unknown for the rows copied from auctions are left null.
+	`create table configSnapshots (
+	id INTEGER not null primary key autoincrement,
+	createdAt INTEGER not null,
+	config TEXT not null
+	);
+
+	create table bids (
+	id INTEGER not null primary key autoincrement,
+	createdAt INTEGER,
+	configSnapshotId INTEGER references configSnapshots(id),
+	account TEXT,
+	auctionId INTEGER not null,
+	market TEXT,
+	debtSymbol TEXT not null,
+	collateralSymbol TEXT not null,
+	blockNumber INTEGER,
+	availableDebt TEXT,
+	availableCollateral TEXT,
+	auctionPrice TEXT,
+	debtPriceUSD TEXT,
+	collateralPriceUSD TEXT,
+	ethPriceUSD TEXT,
+	expectedReceiveDebt TEXT,
+	repayDebt TEXT not null,
+	gasPriceGwei INTEGER,
+	txHash TEXT unique,
+	status TEXT not null,
+	error TEXT not null default ''
+	);
+	create index bidsAuctionId on bids(auctionId);
+
+	create table fills (
+	id INTEGER not null primary key autoincrement,
+	bidId INTEGER not null unique references bids(id),
+	createdAt INTEGER,
+	blockNumber INTEGER,
+	repayDebt TEXT not null,
+	receiveCollateral TEXT not null,
+	gasUsed INTEGER,
+	gasCost TEXT not null
+	);
+
+	create table hedgeOrders (
+	id INTEGER not null primary key autoincrement,
+	bidId INTEGER not null references bids(id),
+	createdAt INTEGER,
+	market TEXT,
+	side TEXT,
+	ddexOrderId TEXT not null,
+	amount TEXT not null,
+	priceLimit TEXT,
+	sellAmount TEXT not null,
+	receiveAmount TEXT not null,
+	avgPrice TEXT,
+	feeRate TEXT,
+	gasFee TEXT,
+	status TEXT not null
+	);
+	create index hedgeOrdersBidId on hedgeOrders(bidId);
+
+	insert into bids(id, auctionId, debtSymbol, collateralSymbol, repayDebt, txHash, status)
+	select rowid, auctionId, debtSymbol, collateralSymbol, repayDebt, txHash,
+	case when receiveCollateral = '0' then 'reverted' else 'filled' end
+	from auctions;
+
+	insert into fills(bidId, repayDebt, receiveCollateral, gasCost)
+	select rowid, repayDebt, receiveCollateral, gasCost from auctions;
+
+	insert into hedgeOrders(bidId, ddexOrderId, amount, sellAmount, receiveAmount, status)
+	select rowid, ddexOrderId, ddexSellCollateral, ddexSellCollateral, ddexReceiveDebt, 'filled'
+	from auctions where ddexOrderId != '0x0';
+
+	drop table auctions;`,
+
+	// 3: write-ahead journal of the bids
+	`create table intents (
+	id INTEGER not null primary key autoincrement,
+	createdAt INTEGER not null,
+	updatedAt INTEGER not null,
+	account TEXT not null,
+	auctionId INTEGER not null,
+	repayDebt TEXT not null,
+	gasPriceGwei INTEGER not null,
+	nonce INTEGER not null,
+	txHash TEXT not null,
+	rawTx TEXT not null,
+	bidId INTEGER references bids(id),
+	hedgeOrderId TEXT not null default '',
+	state TEXT not null,
+	error TEXT not null default ''
+	);
+	create index intentsState on intents(state);`,
+
+	// 4: inputs and outcome of every auction checked
+	`create table decisions (
+	id INTEGER not null primary key autoincrement,
+	createdAt INTEGER not null,
+	configSnapshotId INTEGER references configSnapshots(id),
+	blockNumber INTEGER not null,
+	auctionId INTEGER not null,
+	market TEXT not null,
+	debtSymbol TEXT not null,
+	collateralSymbol TEXT not null,
+	auctionPrice TEXT not null,
+	ratio TEXT not null,
+	availableDebt TEXT not null,
+	availableCollateral TEXT not null,
+	freeDebt TEXT not null,
+	collateralPriceUSD TEXT not null,
+	repayDebt TEXT not null,
+	collateral TEXT not null,
+	expectedReceiveDebt TEXT not null,
+	minReceiveDebt TEXT not null,
+	profitMargin TEXT not null,
+	gasPriceGwei INTEGER not null,
+	action TEXT not null,
+	reason TEXT not null,
+	error TEXT not null default ''
+	);
+	create index decisionsCreatedAt on decisions(createdAt);`,
+}
+
+// NewSqliteStore opens the ledger in a sqlite file, the schema is migrated when opened.
+func NewSqliteStore(path string) (store *SqlStore, err error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return
+	}
+	// sqlite allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err = migrate(db, sqliteMigrations, ""); err != nil {
+		db.Close()
+		return
+	}
+	return newSqlStore(db, false)
+}
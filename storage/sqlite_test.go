@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestSqliteStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sqlite")
+	defer os.RemoveAll(dir)
+
+	store, err := NewSqliteStore(path.Join(dir, "auctionBidderSqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testStore(t, store)
+}
+
+func TestMigrateLegacyDb(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "sqlite")
+	defer os.RemoveAll(dir)
+	dbPath := path.Join(dir, "auctionBidderSqlite")
+
+	// a database created before versioning
+	db, _ := sql.Open("sqlite3", dbPath)
+	defer db.Close()
+	if _, err := db.Exec(sqliteMigrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec(`insert into auctions values('0x01', 1, 'DAI', 'ETH', '100', '1', '0xorder', '1', '102', '0.01')`)
+	db.Exec(`insert into auctions values('0x02', 2, 'DAI', 'ETH', '0', '0', '0x0', '0', '0', '0.002')`)
+
+	for i := 0; i < 2; i++ {
+		store, err := NewSqliteStore(dbPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+	}
+	var version int
+	db.QueryRow("select version from schemaVersion").Scan(&version)
+	if version != len(sqliteMigrations) {
+		t.Errorf("schema version %d, expect %d", version, len(sqliteMigrations))
+	}
+	var status string
+	db.QueryRow("select status from bids where txHash = '0x02'").Scan(&status)
+	if status != BIDREVERTED {
+		t.Errorf("legacy failed bid has status %q", status)
+	}
+
+	store, err := NewSqliteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	position, err := store.QueryPosition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for symbol, expect := range map[string]string{"DAI": "2", "ETH": "-0.012"} {
+		if position[symbol].String() != expect {
+			t.Errorf("%s position %s, expect %s", symbol, position[symbol].String(), expect)
+		}
+	}
+}
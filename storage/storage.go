@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	BIDSENT     = "sent"     // transaction sent, not mined yet
+	BIDFAILED   = "failed"   // transaction not sent
+	BIDFILLED   = "filled"   // mined and received collateral
+	BIDREVERTED = "reverted" // mined but reverted, only gas is paid
+
+	HEDGEFILLED   = "filled"
+	HEDGEPARTIAL  = "partial"
+	HEDGEUNFILLED = "unfilled"
+
+	INTENTSIGNED   = "signed"   // journaled before broadcast, may or may not be sent
+	INTENTSENT     = "sent"     // broadcast and recorded as a bid
+	INTENTMINED    = "mined"    // the fill is recorded
+	INTENTHEDGED   = "hedged"   // the hedge order is placed, not recorded yet
+	INTENTRECORDED = "recorded" // done
+	INTENTFAILED   = "failed"   // not broadcast, done
+
+	DECISIONBID  = "bid"
+	DECISIONSKIP = "skip"
+)
+
+// Store is the trade ledger.
+type Store interface {
+	// InsertConfigSnapshot records the config unless it is the same as the latest snapshot.
+	InsertConfigSnapshot(config string) (id int64, err error)
+	InsertBid(bid *Bid) (id int64, err error)
+	// InsertFill records the mined result and updates the status of the bid.
+	InsertFill(fill *Fill, status string) error
+	InsertHedgeOrder(order *HedgeOrder) error
+	// QueryPosition returns token symbol -> position changed by the bids and hedges.
+	QueryPosition() (map[string]decimal.Decimal, error)
+	// QueryTrades returns the bids matching the filter with their fills and hedge orders, oldest first.
+	QueryTrades(filter Filter) ([]*Trade, error)
+	// InsertIntent journals a signed bid before it is broadcast.
+	InsertIntent(intent *Intent) (id int64, err error)
+	// UpdateIntent records the state, bid, hedge order and error of the intent.
+	UpdateIntent(intent *Intent) error
+	// QueryOpenIntents returns the intents not recorded or failed yet, oldest first.
+	QueryOpenIntents() ([]*Intent, error)
+	InsertDecision(decision *Decision) error
+	// QueryDecisions returns the decisions matching the filter, oldest first.
+	QueryDecisions(filter Filter) ([]*Decision, error)
+	Close() error
+}
+
+// Bid is a fillAuctionWithAmount transaction with the auction state and prices it was decided on.
+type Bid struct {
+	ID                  int64
+	CreatedAt           int64 // unix milliseconds, set by the store, zero if unknown
+	ConfigSnapshotID    int64
+	Account             string
+	AuctionID           int64
+	Market              string
+	DebtSymbol          string
+	CollateralSymbol    string
+	BlockNumber         int64
+	AvailableDebt       decimal.Decimal
+	AvailableCollateral decimal.Decimal
+	AuctionPrice        decimal.Decimal
+	DebtPriceUSD        decimal.Decimal
+	CollateralPriceUSD  decimal.Decimal
+	EthPriceUSD         decimal.Decimal
+	ExpectedReceiveDebt decimal.Decimal // selling the collateral at ddex, estimated by the orderbook
+	RepayDebt           decimal.Decimal
+	GasPriceGwei        int64
+	TxHash              string // empty if the transaction was not sent
+	Status              string
+	Error               string
+}
+
+// Fill is the mined result of a bid.
+type Fill struct {
+	BidID             int64
+	CreatedAt         int64
+	BlockNumber       int64
+	RepayDebt         decimal.Decimal
+	ReceiveCollateral decimal.Decimal
+	GasUsed           int64
+	GasCost           decimal.Decimal // in ETH
+}
+
+// HedgeOrder is a ddex order selling the collateral of a bid. A bid is hedged by one or more orders,
+// each of them may be partially filled.
+type HedgeOrder struct {
+	BidID         int64
+	CreatedAt     int64
+	Market        string
+	Side          string
+	DdexOrderId   string
+	Amount        decimal.Decimal // of the sold asset
+	PriceLimit    decimal.Decimal
+	SellAmount    decimal.Decimal // filled amount of the sold asset
+	ReceiveAmount decimal.Decimal
+	AvgPrice      decimal.Decimal
+	FeeRate       decimal.Decimal
+	GasFee        decimal.Decimal
+	Status        string
+}
+
+// Intent is the write-ahead journal of a bid, from the signed transaction to the recorded hedge order.
+// A bot restarted after a crash resumes the intents left open.
+type Intent struct {
+	ID           int64
+	CreatedAt    int64
+	UpdatedAt    int64
+	Account      string
+	AuctionID    int64
+	RepayDebt    decimal.Decimal
+	GasPriceGwei int64
+	Nonce        int64
+	TxHash       string
+	RawTx        string
+	BidID        int64  // zero until the bid is recorded
+	HedgeOrderId string // empty until the hedge order is placed
+	State        string
+	Error        string
+}
+
+// Decision is the inputs and the outcome of checking an auction in a block, recorded to tune the parameters and to
+// explain missed auctions. Inputs not reached before the decision are zero.
+type Decision struct {
+	ID                  int64
+	CreatedAt           int64
+	ConfigSnapshotID    int64
+	BlockNumber         int64
+	AuctionID           int64
+	Market              string
+	DebtSymbol          string
+	CollateralSymbol    string
+	AuctionPrice        decimal.Decimal
+	Ratio               decimal.Decimal
+	AvailableDebt       decimal.Decimal
+	AvailableCollateral decimal.Decimal
+	FreeDebt            decimal.Decimal // free balance of the debt asset in all accounts
+	CollateralPriceUSD  decimal.Decimal // oracle price
+	RepayDebt           decimal.Decimal // truncated by the max bid values and the balances
+	Collateral          decimal.Decimal // received for RepayDebt
+	ExpectedReceiveDebt decimal.Decimal // selling the collateral, simulated on the orderbook
+	MinReceiveDebt      decimal.Decimal // RepayDebt plus the profit margin
+	ProfitMargin        decimal.Decimal
+	GasPriceGwei        int64
+	Action              string
+	Reason              string
+	Error               string
+}
+
+// Trade is a bid with its mined result and the orders hedging it.
+type Trade struct {
+	Bid         *Bid
+	Fill        *Fill // nil unless mined
+	HedgeOrders []*HedgeOrder
+}
+
+// Filter selects trades by the time their bids were sent. Zero times are unbounded, no markets means all markets.
+type Filter struct {
+	From    time.Time
+	To      time.Time // exclusive
+	Markets []string
+}
+
+func (f *Filter) match(createdAt int64, market string) bool {
+	if (!f.From.IsZero() || !f.To.IsZero()) && createdAt == 0 {
+		return false
+	}
+	if !f.From.IsZero() && createdAt < milliseconds(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && createdAt >= milliseconds(f.To) {
+		return false
+	}
+	if len(f.Markets) == 0 {
+		return true
+	}
+	for _, m := range f.Markets {
+		if m == market {
+			return true
+		}
+	}
+	return false
+}
+
+func milliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+type position map[string]decimal.Decimal
+
+func (p position) add(symbol string, amount decimal.Decimal) {
+	if _, ok := p[symbol]; !ok {
+		p[symbol] = decimal.Zero
+	}
+	p[symbol] = p[symbol].Add(amount)
+}
+
+// a bid repays debt for collateral and pays gas in ETH
+func (p position) addFill(bid *Bid, fill *Fill) {
+	p.add(bid.CollateralSymbol, fill.ReceiveCollateral)
+	p.add(bid.DebtSymbol, fill.RepayDebt.Neg())
+	p.add("ETH", fill.GasCost.Neg())
+}
+
+// a hedge sells the collateral back to debt
+func (p position) addHedge(bid *Bid, order *HedgeOrder) {
+	p.add(bid.CollateralSymbol, order.SellAmount.Neg())
+	p.add(bid.DebtSymbol, order.ReceiveAmount)
+}
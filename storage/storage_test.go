@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"testing"
+	"time"
+)
+
+// the same behavior is expected from every store
+func testStore(t *testing.T, store Store) {
+	id, err := store.InsertBid(&Bid{AuctionID: 3, DebtSymbol: "DAI", CollateralSymbol: "ETH", RepayDebt: decimal.New(50, 0), TxHash: "0x03", Status: BIDSENT})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.InsertBid(&Bid{AuctionID: 3, DebtSymbol: "DAI", CollateralSymbol: "ETH", TxHash: "0x03", Status: BIDSENT}); err == nil {
+		t.Error("duplicate tx hash should be rejected")
+	}
+	if _, err = store.InsertBid(&Bid{AuctionID: 3, DebtSymbol: "DAI", CollateralSymbol: "ETH", Status: BIDFAILED, Error: "nonce too low"}); err != nil {
+		t.Error(err)
+	}
+	if err = store.InsertFill(&Fill{BidID: id, RepayDebt: decimal.New(50, 0), ReceiveCollateral: decimal.New(5, -1), GasCost: decimal.New(1, -2)}, BIDFILLED); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.InsertFill(&Fill{BidID: 1000}, BIDFILLED); err == nil {
+		t.Error("fill of unknown bid should be rejected")
+	}
+	if err = store.InsertHedgeOrder(&HedgeOrder{BidID: id, DdexOrderId: "0xorder", Amount: decimal.New(5, -1), SellAmount: decimal.New(4, -1), ReceiveAmount: decimal.New(41, 0), Status: HEDGEPARTIAL}); err != nil {
+		t.Fatal(err)
+	}
+
+	position, err := store.QueryPosition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for symbol, expect := range map[string]string{"DAI": "-9", "ETH": "0.09"} {
+		if position[symbol].String() != expect {
+			t.Errorf("%s position %s, expect %s", symbol, position[symbol].String(), expect)
+		}
+	}
+
+	id, err = store.InsertBid(&Bid{AuctionID: 4, Market: "ETH-USDT", DebtSymbol: "USDT", CollateralSymbol: "ETH", RepayDebt: decimal.New(10, 0), DebtPriceUSD: decimal.New(1, 0), TxHash: "0x04", Status: BIDSENT})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trades, err := store.QueryTrades(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || trades[0].Fill == nil || len(trades[0].HedgeOrders) != 1 || trades[1].Fill != nil || trades[1].Bid.Error != "nonce too low" {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	if !trades[0].HedgeOrders[0].SellAmount.Equal(decimal.New(4, -1)) || trades[0].Bid.CreatedAt == 0 || trades[0].Bid.TxHash != "0x03" {
+		t.Errorf("unexpected trade %+v %+v", trades[0].Bid, trades[0].HedgeOrders[0])
+	}
+	trades, err = store.QueryTrades(Filter{From: time.Now().Add(-time.Hour), Markets: []string{"ETH-USDT"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].Bid.ID != id || !trades[0].Bid.DebtPriceUSD.Equal(decimal.New(1, 0)) {
+		t.Errorf("unexpected filtered trades %+v", trades)
+	}
+	if trades, _ = store.QueryTrades(Filter{To: time.Now().Add(-time.Hour)}); len(trades) != 0 {
+		t.Errorf("no trade before an hour ago, got %d", len(trades))
+	}
+
+	first, _ := store.InsertConfigSnapshot(`{"MARKETS":"ETH-DAI"}`)
+	second, _ := store.InsertConfigSnapshot(`{"MARKETS":"ETH-DAI"}`)
+	third, _ := store.InsertConfigSnapshot(`{"MARKETS":"ETH-USDT"}`)
+	if first == 0 || first != second || third == first {
+		t.Errorf("unexpected config snapshot ids %d %d %d", first, second, third)
+	}
+
+	intent := &Intent{Account: "0xabc", AuctionID: 4, RepayDebt: decimal.New(10, 0), GasPriceGwei: 5, Nonce: 7, TxHash: "0x05", RawTx: "0xf8", State: INTENTSIGNED}
+	if intent.ID, err = store.InsertIntent(intent); err != nil {
+		t.Fatal(err)
+	}
+	failed := &Intent{Account: "0xabc", AuctionID: 5, RepayDebt: decimal.New(1, 0), TxHash: "0x06", RawTx: "0xf9", State: INTENTSIGNED}
+	if failed.ID, err = store.InsertIntent(failed); err != nil {
+		t.Fatal(err)
+	}
+	intent.State, intent.BidID = INTENTSENT, id
+	failed.State, failed.Error = INTENTFAILED, "nonce too low"
+	if err = store.UpdateIntent(intent); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.UpdateIntent(failed); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.UpdateIntent(&Intent{ID: 1000}); err == nil {
+		t.Error("update of unknown intent should fail")
+	}
+	intents, err := store.QueryOpenIntents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intents) != 1 || intents[0].ID != intent.ID || intents[0].State != INTENTSENT || intents[0].BidID != id ||
+		intents[0].Nonce != 7 || intents[0].RawTx != "0xf8" || !intents[0].RepayDebt.Equal(decimal.New(10, 0)) || intents[0].CreatedAt == 0 {
+		t.Errorf("unexpected open intents %+v", intents)
+	}
+
+	decision := &Decision{BlockNumber: 100, AuctionID: 4, Market: "ETH-USDT", DebtSymbol: "USDT", CollateralSymbol: "ETH",
+		AuctionPrice: decimal.New(150, 0), FreeDebt: decimal.New(1000, 0), ExpectedReceiveDebt: decimal.New(995, 0),
+		MinReceiveDebt: decimal.New(1010, 0), Action: DECISIONSKIP, Reason: "not_profitable"}
+	if err = store.InsertDecision(decision); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.InsertDecision(&Decision{BlockNumber: 100, AuctionID: 5, Market: "ETH-DAI", Action: DECISIONBID, Reason: "profitable"}); err != nil {
+		t.Fatal(err)
+	}
+	decisions, err := store.QueryDecisions(Filter{Markets: []string{"ETH-USDT"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].AuctionID != 4 || decisions[0].Reason != "not_profitable" || decisions[0].CreatedAt == 0 ||
+		!decisions[0].MinReceiveDebt.Equal(decimal.New(1010, 0)) || !decisions[0].Ratio.IsZero() {
+		t.Errorf("unexpected decisions %+v", decisions)
+	}
+	if decisions, err = store.QueryDecisions(Filter{From: time.Now().Add(time.Hour)}); err != nil || len(decisions) != 0 {
+		t.Errorf("unexpected decisions %v %v", decisions, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
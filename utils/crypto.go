@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
 	"golang.org/x/crypto/sha3"
 	"math/big"
@@ -53,6 +55,16 @@ func NewPrivateKeyByHex(privateKeyHex string) (*ecdsa.PrivateKey, error) {
 	return NewPrivateKey(privateKeyBytes)
 }
 
+// DecryptKeystore decrypts an ethereum V3 keystore file, e.g. created by `geth account new`
+func DecryptKeystore(keyJson []byte, password string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(keyJson, password)
+	if err != nil {
+		return nil, err
+	}
+	// convert to the btcec curve used by Sign
+	return NewPrivateKey(math.PaddedBigBytes(key.PrivateKey.D, 32))
+}
+
 func Sign(hash []byte, prv *ecdsa.PrivateKey) ([]byte, error) {
 	if len(hash) != 32 {
 		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
@@ -0,0 +1,339 @@
+// Package bindgen generates typed Go bindings of a contract from its abi, backed by web3.Contract.
+// Calls take a *web3.CallOpts and unpack their outputs, calls with a single output also get a <Method>Call variant
+// to send in a batch by BatchQuery. Transactions are signed and returned for the caller to send, and every event
+// gets a struct and a Parse method decoding its logs.
+package bindgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+type Config struct {
+	Package   string // package of the generated file
+	Type      string // go type of the contract, e.g. Hydro
+	Abi       string // abi json
+	AbiConst  string // go expression of the abi json in the generated file, e.g. utils.HydroAbi
+	AbiImport string // import path of the package of AbiConst
+	Source    string // how the file is generated, for the header
+}
+
+type generator struct {
+	config   Config
+	payable  map[string]bool   // method -> payable
+	structs  map[string]string // struct name -> declaration
+	shapes   map[string]string // tuple name and fields -> struct name
+	imports  map[string]bool   // import paths used by the generated code
+	receiver string
+	body     bytes.Buffer
+}
+
+// Generate returns the formatted source of the bindings.
+func Generate(config Config) (code []byte, err error) {
+	parsed, err := abi.JSON(strings.NewReader(config.Abi))
+	if err != nil {
+		return
+	}
+	var fields []struct {
+		Name    string
+		Type    string
+		Payable bool
+	}
+	if err = json.Unmarshal([]byte(config.Abi), &fields); err != nil {
+		return
+	}
+
+	g := &generator{
+		config:   config,
+		payable:  map[string]bool{},
+		structs:  map[string]string{},
+		shapes:   map[string]string{},
+		imports:  map[string]bool{"auctionBidder/web3": true, config.AbiImport: true},
+		receiver: strings.ToLower(config.Type[:1]),
+	}
+	for _, field := range fields {
+		if field.Type == "function" && field.Payable {
+			g.payable[field.Name] = true
+		}
+	}
+
+	g.constructor()
+	var methods []string
+	for name := range parsed.Methods {
+		methods = append(methods, name)
+	}
+	sort.Strings(methods)
+	for _, name := range methods {
+		if err = g.method(parsed.Methods[name]); err != nil {
+			return
+		}
+	}
+	var events []string
+	for name := range parsed.Events {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	for _, name := range events {
+		if err = g.event(parsed.Events[name]); err != nil {
+			return
+		}
+	}
+
+	return format.Source(g.source())
+}
+
+func (g *generator) source() []byte {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by %s. DO NOT EDIT.\n\n", g.config.Source)
+	fmt.Fprintf(&src, "package %s\n\n", g.config.Package)
+
+	var imports []string
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	src.WriteString("import (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&src, "\t%q\n", path)
+	}
+	src.WriteString(")\n\n")
+
+	var names []string
+	for name := range g.structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		src.WriteString(g.structs[name])
+	}
+
+	src.Write(g.body.Bytes())
+	return src.Bytes()
+}
+
+func (g *generator) constructor() {
+	t := g.config.Type
+	fmt.Fprintf(&g.body, "// %s is the contract at an address, its methods call it through the web3 node.\n", t)
+	fmt.Fprintf(&g.body, "type %s struct {\n\t*web3.Contract\n}\n\n", t)
+	fmt.Fprintf(&g.body, "func New%s(w *web3.Web3, address string) (contract *%s, err error) {\n", t, t)
+	fmt.Fprintf(&g.body, "\tc, err := w.NewContract(%s, address)\n\tif err != nil {\n\t\treturn\n\t}\n", g.config.AbiConst)
+	fmt.Fprintf(&g.body, "\treturn &%s{c}, nil\n}\n\n", t)
+}
+
+type param struct {
+	name   string
+	goType string
+}
+
+func (g *generator) method(method abi.Method) (err error) {
+	inputs, err := g.params(method.Inputs, method.Name, map[string]bool{"opts": true, "tx": true, "value": true})
+	if err != nil {
+		return
+	}
+	var args []string
+	var names []string
+	for _, input := range inputs {
+		args = append(args, input.name+" "+input.goType)
+		names = append(names, input.name)
+	}
+	call := fmt.Sprintf("%q", method.Name)
+	if len(names) > 0 {
+		call += ", " + strings.Join(names, ", ")
+	}
+	goName := abi.ToCamelCase(method.Name)
+
+	if !method.Const {
+		value := "big.NewInt(0)"
+		if g.payable[method.Name] {
+			args = append([]string{"value *big.Int"}, args...)
+			value = "value"
+		}
+		g.imports["math/big"] = true
+		fmt.Fprintf(&g.body, "// %s signs a transaction calling %s, it is not sent.\n", goName, method.Sig())
+		fmt.Fprintf(&g.body, "func (%s *%s) %s(%s) (txHash string, rawTx string, err error) {\n",
+			g.receiver, g.config.Type, goName, strings.Join(append([]string{"tx *web3.SendTxParams"}, args...), ", "))
+		fmt.Fprintf(&g.body, "\treturn %s.Sign(tx, %s, %s)\n}\n\n", g.receiver, value, call)
+		return
+	}
+
+	taken := map[string]bool{"opts": true, "err": true}
+	for _, name := range names {
+		taken[name] = true
+	}
+	args = append([]string{"opts *web3.CallOpts"}, args...)
+	fmt.Fprintf(&g.body, "// %s calls %s.\n", goName, method.Sig())
+
+	switch len(method.Outputs) {
+	case 0:
+		fmt.Fprintf(&g.body, "func (%s *%s) %s(%s) (err error) {\n", g.receiver, g.config.Type, goName, strings.Join(args, ", "))
+		fmt.Fprintf(&g.body, "\treturn %s.Query(opts, nil, %s)\n}\n\n", g.receiver, call)
+	case 1:
+		output := method.Outputs[0]
+		name := safeName(output.Name, "out", taken)
+		var goType string
+		if goType, err = g.goType(output.Type, strings.TrimPrefix(method.Name, "get"), method.Name); err != nil {
+			return
+		}
+		fmt.Fprintf(&g.body, "func (%s *%s) %s(%s) (%s %s, err error) {\n", g.receiver, g.config.Type, goName, strings.Join(args, ", "), name, goType)
+		fmt.Fprintf(&g.body, "\terr = %s.Query(opts, &%s, %s)\n\treturn\n}\n\n", g.receiver, name, call)
+
+		// the same call to send in a batch
+		batchArgs := append([]string{args[0], name + " *" + goType}, args[1:]...)
+		fmt.Fprintf(&g.body, "// %sCall is %s for BatchQuery, %s is set once the batch is sent.\n", goName, goName, name)
+		fmt.Fprintf(&g.body, "func (%s *%s) %sCall(%s) *web3.ContractCall {\n", g.receiver, g.config.Type, goName, strings.Join(batchArgs, ", "))
+		fmt.Fprintf(&g.body, "\treturn %s.NewCall(opts, %s, %s)\n}\n\n", g.receiver, name, call)
+	default:
+		// outputs are unpacked by name into a struct with a field each
+		var results []string
+		var fields []string
+		var values []string
+		for _, output := range method.Outputs {
+			if output.Name == "" {
+				return fmt.Errorf("bindgen: unnamed outputs of %s", method.Sig())
+			}
+			var goType string
+			if goType, err = g.goType(output.Type, output.Name, method.Name); err != nil {
+				return
+			}
+			results = append(results, safeName(output.Name, "out", taken)+" "+goType)
+			fields = append(fields, fmt.Sprintf("\t\t%s %s\n", abi.ToCamelCase(output.Name), goType))
+			values = append(values, "outputs."+abi.ToCamelCase(output.Name))
+		}
+		fmt.Fprintf(&g.body, "func (%s *%s) %s(%s) (%s, err error) {\n", g.receiver, g.config.Type, goName, strings.Join(args, ", "), strings.Join(results, ", "))
+		fmt.Fprintf(&g.body, "\tvar outputs struct {\n%s\t}\n", strings.Join(fields, ""))
+		fmt.Fprintf(&g.body, "\terr = %s.Query(opts, &outputs, %s)\n", g.receiver, call)
+		fmt.Fprintf(&g.body, "\treturn %s, err\n}\n\n", strings.Join(values, ", "))
+	}
+	return
+}
+
+func (g *generator) event(event abi.Event) (err error) {
+	name := abi.ToCamelCase(event.Name) + "Event"
+	var fields []string
+	for _, input := range event.Inputs {
+		if input.Indexed && (input.Type.T == abi.StringTy || input.Type.T == abi.BytesTy || input.Type.T == abi.SliceTy || input.Type.T == abi.TupleTy) {
+			return fmt.Errorf("bindgen: indexed %s of %s is only a hash in the logs", input.Name, event.Sig())
+		}
+		var goType string
+		if goType, err = g.goType(input.Type, input.Name, event.Name); err != nil {
+			return
+		}
+		fields = append(fields, fmt.Sprintf("\t%s %s\n", abi.ToCamelCase(input.Name), goType))
+	}
+
+	fmt.Fprintf(&g.body, "// %s is a %s log.\n", name, event.Sig())
+	fmt.Fprintf(&g.body, "type %s struct {\n%s}\n\n", name, strings.Join(fields, ""))
+	fmt.Fprintf(&g.body, "// Parse%s decodes a %s log, it fails if the log is another event.\n", abi.ToCamelCase(event.Name), event.Name)
+	fmt.Fprintf(&g.body, "func (%s *%s) Parse%s(log web3.Log) (event *%s, err error) {\n", g.receiver, g.config.Type, abi.ToCamelCase(event.Name), name)
+	fmt.Fprintf(&g.body, "\tevent = &%s{}\n\tif err = %s.DecodeLog(event, %q, log); err != nil {\n\t\treturn nil, err\n\t}\n\treturn\n}\n\n", name, g.receiver, event.Name)
+	return
+}
+
+func (g *generator) params(args abi.Arguments, method string, taken map[string]bool) (params []param, err error) {
+	taken[g.receiver] = true
+	for i, arg := range args {
+		var goType string
+		if goType, err = g.goType(arg.Type, arg.Name, method); err != nil {
+			return
+		}
+		params = append(params, param{safeName(arg.Name, fmt.Sprintf("arg%d", i), taken), goType})
+	}
+	return
+}
+
+// safeName makes an abi name a go identifier not already taken
+func safeName(name string, fallback string, taken map[string]bool) string {
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		name = fallback
+	}
+	for token.IsKeyword(name) || taken[name] {
+		name += "_"
+	}
+	taken[name] = true
+	return name
+}
+
+// goType is the go type an abi type packs from and unpacks into, tuples are named after name, or method and name
+// if another tuple already has the name
+func (g *generator) goType(t abi.Type, name string, method string) (goType string, err error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		switch t.Size {
+		case 8, 16, 32, 64:
+			goType = fmt.Sprintf("int%d", t.Size)
+			if t.T == abi.UintTy {
+				goType = "u" + goType
+			}
+		default:
+			g.imports["math/big"] = true
+			goType = "*big.Int"
+		}
+	case abi.BoolTy:
+		goType = "bool"
+	case abi.StringTy:
+		goType = "string"
+	case abi.AddressTy:
+		g.imports["github.com/ethereum/go-ethereum/common"] = true
+		goType = "common.Address"
+	case abi.HashTy:
+		g.imports["github.com/ethereum/go-ethereum/common"] = true
+		goType = "common.Hash"
+	case abi.BytesTy:
+		goType = "[]byte"
+	case abi.FixedBytesTy:
+		goType = fmt.Sprintf("[%d]byte", t.Size)
+	case abi.SliceTy:
+		if goType, err = g.goType(*t.Elem, strings.TrimSuffix(name, "s"), method); err == nil {
+			goType = "[]" + goType
+		}
+	case abi.ArrayTy:
+		if goType, err = g.goType(*t.Elem, strings.TrimSuffix(name, "s"), method); err == nil {
+			goType = fmt.Sprintf("[%d]%s", t.Size, goType)
+		}
+	case abi.TupleTy:
+		goType, err = g.tuple(t, name, method)
+	default:
+		err = fmt.Errorf("bindgen: unsupported type %s of %s in %s", t.String(), name, method)
+	}
+	return
+}
+
+// tuple declares a struct for the tuple, tuples with the same fields share it
+func (g *generator) tuple(t abi.Type, name string, method string) (goType string, err error) {
+	var shape []string
+	for i, elem := range t.TupleElems {
+		shape = append(shape, t.TupleRawNames[i]+" "+elem.String())
+	}
+	// the same fields under another name get their own struct, e.g. the taker and the maker order params
+	key := abi.ToCamelCase(name) + "(" + strings.Join(shape, ",") + ")"
+	if structName, ok := g.shapes[key]; ok {
+		return structName, nil
+	}
+
+	var fields []string
+	for i, elem := range t.TupleElems {
+		var fieldType string
+		if fieldType, err = g.goType(*elem, t.TupleRawNames[i], method); err != nil {
+			return
+		}
+		fields = append(fields, fmt.Sprintf("\t%s %s\n", abi.ToCamelCase(t.TupleRawNames[i]), fieldType))
+	}
+
+	goType = abi.ToCamelCase(name)
+	if _, taken := g.structs[goType]; taken || goType == "" || goType == g.config.Type {
+		goType = abi.ToCamelCase(method) + goType
+	}
+	if _, taken := g.structs[goType]; taken {
+		return "", fmt.Errorf("bindgen: two tuples named %s in %s", goType, method)
+	}
+	g.shapes[key] = goType
+	g.structs[goType] = fmt.Sprintf("// %s is a %s tuple.\ntype %s struct {\n%s}\n\n", goType, t.String(), goType, strings.Join(fields, ""))
+	return
+}
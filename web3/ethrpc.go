@@ -3,6 +3,7 @@ package web3
 // 6b8e9c0e9a8ffd2154cd4470a6ffb4919885e788
 
 import (
+	"auctionBidder/metrics"
 	"auctionBidder/utils"
 	"bytes"
 	"encoding/json"
@@ -12,6 +13,8 @@ import (
 	"math/big"
 	"net/http"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
 // EthError - ethereum error
@@ -44,6 +47,10 @@ type EthRPC struct {
 	client httpClient
 	log    logger
 	Debug  bool
+	lastID int64 // requests get increasing ids, responses of a batch are matched by them
+
+	duration *metrics.Histogram
+	errors   *metrics.Counter
 }
 
 // New create new Rpc client with given url
@@ -52,6 +59,9 @@ func New(url string, options ...func(rpc *EthRPC)) *EthRPC {
 		url:    url,
 		client: http.DefaultClient,
 		log:    log.New(os.Stderr, "", log.LstdFlags),
+
+		duration: metrics.RPCDuration,
+		errors:   metrics.RPCErrors,
 	}
 	for _, option := range options {
 		option(rpc)
@@ -85,46 +95,123 @@ func (rpc *EthRPC) URL() string {
 
 // Call returns raw response of method call
 func (rpc *EthRPC) Call(method string, params ...interface{}) (json.RawMessage, error) {
-	request := ethRequest{
-		ID:      1,
+	start := time.Now()
+	result, err := rpc.post(method, params...)
+	rpc.duration.Observe(time.Since(start).Seconds(), method)
+	if err != nil {
+		rpc.errors.Inc(method)
+	}
+	return result, err
+}
+
+func (rpc *EthRPC) post(method string, params ...interface{}) (json.RawMessage, error) {
+	request := rpc.newRequest(method, params)
+
+	resp := new(ethResponse)
+	if err := rpc.send(method, request, resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, *resp.Error
+	}
+
+	return resp.Result, nil
+}
+
+func (rpc *EthRPC) newRequest(method string, params []interface{}) ethRequest {
+	return ethRequest{
+		ID:      int(atomic.AddInt64(&rpc.lastID, 1)),
 		JSONRPC: "2.0",
 		Method:  method,
 		Params:  params,
 	}
+}
 
+// send posts the request and unmarshals the response, name is only for the debug log
+func (rpc *EthRPC) send(name string, request interface{}, response interface{}) error {
 	body, err := json.Marshal(request)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	response, err := rpc.client.Post(rpc.url, "application/json", bytes.NewBuffer(body))
-	if response != nil {
-		defer response.Body.Close()
+	httpResponse, err := rpc.client.Post(rpc.url, "application/json", bytes.NewBuffer(body))
+	if httpResponse != nil {
+		defer httpResponse.Body.Close()
 	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	data, err := ioutil.ReadAll(response.Body)
+	data, err := ioutil.ReadAll(httpResponse.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if rpc.Debug {
-		rpc.log.Println(fmt.Sprintf("%s\nRequest: %s\nResponse: %s\n", method, body, data))
+		rpc.log.Println(fmt.Sprintf("%s\nRequest: %s\nResponse: %s\n", name, body, data))
 	}
 
-	resp := new(ethResponse)
-	if err := json.Unmarshal(data, resp); err != nil {
-		return nil, err
+	return json.Unmarshal(data, response)
+}
+
+// BatchElem is a request of a batch. Once the batch is sent, the result is unmarshalled into Result if it is not nil,
+// or Error is the error of the request.
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	Result interface{}
+	Error  error
+}
+
+// BatchCall sends the requests in a single http round trip, the node answers them in any order.
+// err is the error of the round trip, the errors of the requests are in their elems.
+func (rpc *EthRPC) BatchCall(elems []*BatchElem) (err error) {
+	if len(elems) == 0 {
+		return
+	}
+	start := time.Now()
+	defer func() {
+		rpc.duration.Observe(time.Since(start).Seconds(), "batch")
+		for _, elem := range elems {
+			if elem.Error != nil {
+				rpc.errors.Inc(elem.Method)
+			}
+		}
+	}()
+
+	requests := make([]ethRequest, len(elems))
+	byID := map[int]*BatchElem{}
+	for i, elem := range elems {
+		requests[i] = rpc.newRequest(elem.Method, elem.Params)
+		byID[requests[i].ID] = elem
 	}
 
-	if resp.Error != nil {
-		return nil, *resp.Error
+	var responses []ethResponse
+	if err = rpc.send("batch", requests, &responses); err != nil {
+		for _, elem := range elems {
+			elem.Error = err
+		}
+		return
 	}
 
-	return resp.Result, nil
+	for _, response := range responses {
+		elem, ok := byID[response.ID]
+		if !ok {
+			continue
+		}
+		delete(byID, response.ID)
+		if response.Error != nil {
+			elem.Error = *response.Error
+		} else if elem.Result != nil {
+			elem.Error = json.Unmarshal(response.Result, elem.Result)
+		}
+	}
+	for id, elem := range byID {
+		elem.Error = fmt.Errorf("no response to request %d in the batch", id)
+	}
 
+	return
 }
 
 // RawCall returns raw response of method call (Deprecated)
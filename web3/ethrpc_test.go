@@ -0,0 +1,87 @@
+package web3
+
+import (
+	"auctionBidder/metrics"
+	"bytes"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []ethRequest
+		json.NewDecoder(r.Body).Decode(&requests)
+		// answered in reverse order, the last request gets an error and the first one no response
+		var responses []map[string]interface{}
+		for i := len(requests) - 1; i > 0; i-- {
+			response := map[string]interface{}{"id": requests[i].ID, "jsonrpc": "2.0"}
+			if i == len(requests)-1 {
+				response["error"] = EthError{-32000, "execution reverted"}
+			} else {
+				response["result"] = requests[i].Method
+			}
+			responses = append(responses, response)
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer server.Close()
+
+	rpc := NewEthRPC(server.URL)
+	var results [4]string
+	elems := make([]*BatchElem, len(results))
+	for i := range elems {
+		elems[i] = &BatchElem{Method: string(rune('a' + i)), Result: &results[i]}
+	}
+	if err := rpc.BatchCall(elems); err != nil {
+		t.Fatal(err)
+	}
+	if elems[0].Error == nil || elems[3].Error == nil || elems[3].Error.(EthError).Code != -32000 {
+		t.Errorf("unexpected errors %v %v", elems[0].Error, elems[3].Error)
+	}
+	if elems[1].Error != nil || elems[2].Error != nil || results[1] != "b" || results[2] != "c" {
+		t.Errorf("results not matched by id %v", results)
+	}
+}
+
+func TestBatchQueryCountsReverts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []ethRequest
+		json.NewDecoder(r.Body).Decode(&requests)
+		// the second call reverts with an empty output
+		var responses []map[string]interface{}
+		for i, request := range requests {
+			result := "0x000000000000000000000000241e82c79452f51fbfc89fac6d912e021db1a3b7"
+			if i == 1 {
+				result = "0x"
+			}
+			responses = append(responses, map[string]interface{}{"id": request.ID, "jsonrpc": "2.0", "result": result})
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer server.Close()
+
+	web3 := NewWeb3(server.URL)
+	errors := metrics.NewCounter("test_batch_query_errors_total", "Failed calls of the test.", "method")
+	web3.Rpc = NewEthRPC(server.URL, WithMetrics(metrics.NewHistogram("test_batch_query_duration_seconds", "Latency of the test.", metrics.DefaultBuckets, "method"), errors))
+	contract, err := web3.NewContract(`[{"constant":true,"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"type":"function"}]`, "0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners := make([]common.Address, 2)
+	calls := []*ContractCall{contract.NewCall(nil, &owners[0], "owner"), contract.NewCall(nil, &owners[1], "owner")}
+	if err = contract.BatchQuery(calls); err != nil {
+		t.Fatal(err)
+	}
+	if calls[0].Error != nil || calls[1].Error != ErrEmptyOutput {
+		t.Errorf("unexpected errors %v %v", calls[0].Error, calls[1].Error)
+	}
+	var scrape bytes.Buffer
+	metrics.DefaultRegistry.Write(&scrape)
+	if !strings.Contains(scrape.String(), `test_batch_query_errors_total{method="eth_call"} 1`) {
+		t.Errorf("the reverted call should be counted as failed")
+	}
+}
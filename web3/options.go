@@ -1,6 +1,7 @@
 package web3
 
 import (
+	"auctionBidder/metrics"
 	"io"
 	"net/http"
 )
@@ -33,3 +34,11 @@ func WithDebug(enabled bool) func(rpc *EthRPC) {
 		rpc.Debug = enabled
 	}
 }
+
+// WithMetrics set the metrics of the calls, by default the ethereum node rpc metrics
+func WithMetrics(duration *metrics.Histogram, errors *metrics.Counter) func(rpc *EthRPC) {
+	return func(rpc *EthRPC) {
+		rpc.duration = duration
+		rpc.errors = errors
+	}
+}
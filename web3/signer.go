@@ -0,0 +1,142 @@
+package web3
+
+import (
+	"auctionBidder/metrics"
+	"auctionBidder/utils"
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"math/big"
+	"strings"
+)
+
+// Signer signs transactions and personal messages for one address.
+type Signer interface {
+	Address() string
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignPersonalMessage returns a 65 bytes signature of "\x19Ethereum Signed Message:\n" + len(message) + message, v is 0 or 1
+	SignPersonalMessage(message []byte) ([]byte, error)
+}
+
+// LocalSigner signs with a private key held in memory.
+type LocalSigner struct {
+	address    string
+	privateKey *ecdsa.PrivateKey
+}
+
+func NewLocalSigner(privateKey *ecdsa.PrivateKey) *LocalSigner {
+	return &LocalSigner{utils.PubKey2Address(privateKey.PublicKey), privateKey}
+}
+
+func (s *LocalSigner) Address() string {
+	return s.address
+}
+
+func (s *LocalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.NewEIP155Signer(chainID), s.privateKey)
+}
+
+func (s *LocalSigner) SignPersonalMessage(message []byte) ([]byte, error) {
+	return utils.PersonalSignByPrivateKey(message, s.privateKey)
+}
+
+// RemoteSigner asks a separate signing process, e.g. clef, to sign over json rpc.
+type RemoteSigner struct {
+	address string
+	rpc     *EthRPC
+}
+
+func NewRemoteSigner(url string, address string) *RemoteSigner {
+	// signer calls are not node calls, keep them out of the node rpc metrics
+	return &RemoteSigner{strings.ToLower(address), NewEthRPC(url, WithMetrics(metrics.SignerDuration, metrics.SignerErrors))}
+}
+
+func (s *RemoteSigner) Address() string {
+	return s.address
+}
+
+type signTxArgs struct {
+	From     common.MixedcaseAddress  `json:"from"`
+	To       *common.MixedcaseAddress `json:"to"`
+	Gas      string                   `json:"gas"`
+	GasPrice string                   `json:"gasPrice"`
+	Value    string                   `json:"value"`
+	Nonce    string                   `json:"nonce"`
+	Data     string                   `json:"data"`
+}
+
+type signTxResult struct {
+	Raw string `json:"raw"`
+}
+
+// SignTx calls account_signTransaction. The chain id is configured in the remote signer,
+// the returned transaction is checked against chainID and the signer address.
+func (s *RemoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (signedTx *types.Transaction, err error) {
+	args := signTxArgs{
+		From:     common.NewMixedcaseAddress(common.HexToAddress(s.address)),
+		Gas:      utils.Int2HexString(int(tx.Gas())),
+		GasPrice: utils.BigIntToHexString(*tx.GasPrice()),
+		Value:    utils.BigIntToHexString(*tx.Value()),
+		Nonce:    utils.Int2HexString(int(tx.Nonce())),
+		Data:     utils.Bytes2HexString(tx.Data()),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+
+	resp, err := s.rpc.Call("account_signTransaction", args)
+	if err != nil {
+		return
+	}
+	var result signTxResult
+	if err = json.Unmarshal(resp, &result); err != nil {
+		return
+	}
+
+	signedTx = new(types.Transaction)
+	if err = rlp.DecodeBytes(utils.HexString2Bytes(result.Raw), signedTx); err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.NewEIP155Signer(chainID), signedTx)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IsAddressEqual(from.Hex(), s.address) {
+		return nil, fmt.Errorf("remote signer returned a transaction signed by %s", from.Hex())
+	}
+	if signedTx.Nonce() != tx.Nonce() ||
+		signedTx.Gas() != tx.Gas() ||
+		signedTx.GasPrice().Cmp(tx.GasPrice()) != 0 ||
+		signedTx.Value().Cmp(tx.Value()) != 0 ||
+		!bytes.Equal(signedTx.Data(), tx.Data()) ||
+		(tx.To() != nil && (signedTx.To() == nil || *signedTx.To() != *tx.To())) {
+		return nil, fmt.Errorf("remote signer returned a different transaction")
+	}
+	return
+}
+
+// SignPersonalMessage calls account_signData with content type text/plain.
+func (s *RemoteSigner) SignPersonalMessage(message []byte) (signature []byte, err error) {
+	resp, err := s.rpc.Call("account_signData", "text/plain", common.NewMixedcaseAddress(common.HexToAddress(s.address)), utils.Bytes2HexString(message))
+	if err != nil {
+		return
+	}
+	var signatureHex string
+	if err = json.Unmarshal(resp, &signatureHex); err != nil {
+		return
+	}
+
+	signature = utils.HexString2Bytes(signatureHex)
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("invalid signature length %d", len(signature))
+	}
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package web3
+
+import (
+	"auctionBidder/metrics"
+	"auctionBidder/utils"
+	"bytes"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testPrivateKey = "B7A0C9D2786FC4DD080EA5D619D36771AEB0C8C26C290AFD3451B92BA2B7BC2C"
+
+// a clef-like signer backed by a local key
+func newStubSigner(t *testing.T, chainID *big.Int) *httptest.Server {
+	privateKey, _ := utils.NewPrivateKeyByHex(testPrivateKey)
+	local := NewLocalSigner(privateKey)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var request struct {
+			ID     int               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(body, &request)
+
+		var result interface{}
+		switch request.Method {
+		case "account_signTransaction":
+			var args struct {
+				To       common.Address `json:"to"`
+				Gas      string         `json:"gas"`
+				GasPrice string         `json:"gasPrice"`
+				Value    string         `json:"value"`
+				Nonce    string         `json:"nonce"`
+				Data     string         `json:"data"`
+			}
+			json.Unmarshal(request.Params[0], &args)
+			gas, _ := utils.HexString2Int(args.Gas)
+			nonce, _ := utils.HexString2Int(args.Nonce)
+			gasPrice, _ := utils.HexString2BigInt(args.GasPrice)
+			value, _ := utils.HexString2BigInt(args.Value)
+			tx := types.NewTransaction(uint64(nonce), args.To, &value, uint64(gas), &gasPrice, utils.HexString2Bytes(args.Data))
+			signedTx, err := local.SignTx(tx, chainID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			raw, _ := rlp.EncodeToBytes(signedTx)
+			result = map[string]interface{}{"raw": utils.Bytes2HexString(raw), "tx": signedTx}
+		case "account_signData":
+			var data string
+			json.Unmarshal(request.Params[2], &data)
+			signature, _ := local.SignPersonalMessage(utils.HexString2Bytes(data))
+			signature[64] += 27
+			result = utils.Bytes2HexString(signature)
+		}
+
+		resp, _ := json.Marshal(map[string]interface{}{"id": request.ID, "jsonrpc": "2.0", "result": result})
+		w.Write(resp)
+	}))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	chainID := big.NewInt(3)
+	server := newStubSigner(t, chainID)
+	defer server.Close()
+
+	privateKey, _ := utils.NewPrivateKeyByHex(testPrivateKey)
+	address := utils.PubKey2Address(privateKey.PublicKey)
+	signer := NewRemoteSigner(server.URL, address)
+
+	tx := types.NewTransaction(7, common.HexToAddress("0x241e82C79452F51fbfc89Fac6d912e021dB1a3B7"), big.NewInt(0), 500000, big.NewInt(20000000000), []byte{1, 2, 3})
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := types.Sender(types.NewEIP155Signer(chainID), signedTx)
+	if !utils.IsAddressEqual(from.Hex(), address) {
+		t.Errorf("transaction signed by %s, expect %s", from.Hex(), address)
+	}
+
+	if _, err = signer.SignTx(tx, big.NewInt(1)); err == nil {
+		t.Error("transaction signed for another chain should be rejected")
+	}
+
+	message := []byte("HYDRO-AUTHENTICATION@1571000000000")
+	signature, err := signer.SignPersonalMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := utils.PersonalEcRecover(message, signature)
+	if err != nil || !utils.IsAddressEqual(recovered, address) {
+		t.Errorf("message signed by %s, expect %s", recovered, address)
+	}
+
+	var scrape bytes.Buffer
+	metrics.DefaultRegistry.Write(&scrape)
+	if strings.Contains(scrape.String(), `auction_bidder_rpc_duration_seconds_count{method="account_signTransaction"}`) {
+		t.Error("signer calls should not be counted as node rpc calls")
+	}
+	if !strings.Contains(scrape.String(), `auction_bidder_signer_duration_seconds_count{method="account_signTransaction"} 2`) {
+		t.Error("signer calls should be counted in the signer metrics")
+	}
+}
@@ -1,35 +1,34 @@
 package web3
 
 import (
+	"auctionBidder/metrics"
 	"auctionBidder/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	"math/big"
 	"os"
 	"strings"
 )
 
 type Web3 struct {
-	Rpc           *EthRPC
-	privateKeyMap map[string]string // address -> privateKey
+	Rpc       *EthRPC
+	signerMap map[string]Signer // address -> signer
 }
 
 func NewWeb3(ethereumNodeUrl string) *Web3 {
 	rpc := NewEthRPC(ethereumNodeUrl)
 
-	return &Web3{rpc, map[string]string{}}
+	return &Web3{rpc, map[string]Signer{}}
 }
 
-func (w *Web3) AddPrivateKey(privateKey string) (newAddress string, err error) {
-	pk, err := utils.NewPrivateKeyByHex(privateKey)
-	if err != nil {
-		return
-	}
-	newAddress = utils.PubKey2Address(pk.PublicKey)
-	w.privateKeyMap[strings.ToLower(newAddress)] = strings.ToLower(privateKey)
+func (w *Web3) AddSigner(signer Signer) (newAddress string) {
+	newAddress = signer.Address()
+	w.signerMap[strings.ToLower(newAddress)] = signer
 
 	return
 }
@@ -41,6 +40,7 @@ func (w *Web3) NewBlockChannel() chan int64 {
 		for true {
 			newBlockNum, err := w.Rpc.EthBlockNumber()
 			if err == nil {
+				metrics.HeadBlock.Set(float64(newBlockNum))
 				if newBlockNum > blockNum {
 					c <- int64(newBlockNum)
 					blockNum = newBlockNum
@@ -79,7 +79,46 @@ func (w *Web3) NewContract(abiStr string, address string) (contract *Contract, e
 	return
 }
 
-func (c *Contract) Call(functionName string, args ...interface{}) (resp string, err error) {
+func (c *Contract) Address() string {
+	return c.address.Hex()
+}
+
+func (c *Contract) call(opts *CallOpts, functionName string, args ...interface{}) (resp string, err error) {
+	tx, tag, err := c.callParams(opts, functionName, args...)
+	if err != nil {
+		return
+	}
+	return c.web3.Rpc.EthCall(tx, tag)
+}
+
+// CallOpts are the sender and the block of a call, nil calls from the zero address on the latest block.
+// Past blocks need a node that still has their state, old blocks need an archive node.
+type CallOpts struct {
+	From     string
+	BlockNum int64 // latest if not positive
+}
+
+// Query calls functionName and unpacks the outputs into v, a pointer to the single output or to a struct with a field
+// per output. Fields are matched to the outputs by name, e.g. leftDebtAmount to LeftDebtAmount, tuple outputs unpack
+// into nested structs. The outputs are dropped if v is nil.
+func (c *Contract) Query(opts *CallOpts, v interface{}, functionName string, args ...interface{}) (err error) {
+	resp, err := c.call(opts, functionName, args...)
+	if err != nil || v == nil {
+		return
+	}
+	return c.Unpack(v, functionName, resp)
+}
+
+// callParams are the params of eth_call
+func (c *Contract) callParams(opts *CallOpts, functionName string, args ...interface{}) (tx T, tag string, err error) {
+	from, tag := "0x0000000000000000000000000000000000000000", "latest"
+	if opts != nil && opts.From != "" {
+		from = opts.From
+	}
+	if opts != nil && opts.BlockNum > 0 {
+		tag = fmt.Sprintf("0x%x", opts.BlockNum)
+	}
+
 	var dataByte []byte
 	if args != nil {
 		dataByte, err = c.abi.Pack(functionName, args...)
@@ -90,16 +129,129 @@ func (c *Contract) Call(functionName string, args ...interface{}) (resp string,
 		return
 	}
 
-	return c.web3.Rpc.EthCall(T{
+	tx = T{
 		To:   c.address.String(),
-		From: "0x0000000000000000000000000000000000000000",
-		Data: fmt.Sprintf("0x%x", dataByte)},
-		"latest",
-	)
+		From: from,
+		Data: fmt.Sprintf("0x%x", dataByte),
+	}
+	return
+}
+
+// ContractCall is a call of a batch, its outputs are unpacked into v once the batch is sent, or Error is set
+type ContractCall struct {
+	contract     *Contract
+	opts         *CallOpts
+	v            interface{}
+	functionName string
+	args         []interface{}
+	Error        error
+}
+
+// NewCall is Query to send in a batch by BatchQuery
+func (c *Contract) NewCall(opts *CallOpts, v interface{}, functionName string, args ...interface{}) *ContractCall {
+	return &ContractCall{c, opts, v, functionName, args, nil}
+}
+
+// BatchQuery sends the calls, of any contract on the node of c, in a single request.
+// err is the error of the request, the errors of the calls are in their Error.
+func (c *Contract) BatchQuery(calls []*ContractCall) (err error) {
+	var elems []*BatchElem
+	var sent []*ContractCall
+	resps := make([]string, len(calls))
+	for i, call := range calls {
+		var tx T
+		var tag string
+		if tx, tag, call.Error = call.contract.callParams(call.opts, call.functionName, call.args...); call.Error != nil {
+			continue
+		}
+		elems = append(elems, &BatchElem{Method: "eth_call", Params: []interface{}{tx, tag}, Result: &resps[i]})
+		sent = append(sent, call)
+	}
+
+	err = c.web3.Rpc.BatchCall(elems)
+	for i, call := range sent {
+		if call.Error = elems[i].Error; call.Error == nil && call.v != nil {
+			// an empty output is a reverted call, counted as a failed call like the json-rpc errors
+			if call.Error = call.contract.Unpack(call.v, call.functionName, *elems[i].Result.(*string)); call.Error != nil {
+				c.web3.Rpc.errors.Inc(elems[i].Method)
+			}
+		}
+	}
+	return
+}
+
+// Unpack decodes the hex outputs of a call to functionName into v, as Query does
+func (c *Contract) Unpack(v interface{}, functionName string, resp string) error {
+	method, ok := c.abi.Methods[functionName]
+	if !ok {
+		return fmt.Errorf("abi: no method %s", functionName)
+	}
+	data := utils.HexString2Bytes(resp)
+	if len(data) == 0 {
+		return ErrEmptyOutput
+	}
+	if len(data)%32 != 0 {
+		return fmt.Errorf("abi: %d bytes of output are not the outputs of %s", len(data), method.Sig())
+	}
+	return method.Outputs.Unpack(v, data)
+}
+
+// ErrEmptyOutput is returned when a call returns nothing, the contract doesn't exist or the call reverted
+var ErrEmptyOutput = errors.New("abi: empty output")
+
+// DecodeLog unpacks a log of the contract into v, a pointer to a struct with a field per event argument, indexed or not.
+// It fails if the log is another event.
+func (c *Contract) DecodeLog(v interface{}, eventName string, log Log) (err error) {
+	event, ok := c.abi.Events[eventName]
+	if !ok {
+		return fmt.Errorf("abi: no event %s", eventName)
+	}
+	if len(log.Topics) == 0 || common.HexToHash(log.Topics[0]) != event.ID() {
+		return fmt.Errorf("abi: log is not a %s event", eventName)
+	}
+
+	// indexed arguments are the topics after the event id, one word each
+	var indexed abi.Arguments
+	var topics []byte
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			if len(log.Topics) <= len(indexed)+1 {
+				return fmt.Errorf("abi: %s event without topic %s", eventName, input.Name)
+			}
+			input.Indexed = false
+			indexed = append(indexed, input)
+			topics = append(topics, common.HexToHash(log.Topics[len(indexed)]).Bytes()...)
+		}
+	}
+	if len(indexed) > 0 {
+		if err = indexed.Unpack(v, topics); err != nil {
+			return
+		}
+	}
+	if event.Inputs.LengthNonIndexed() > 0 {
+		err = event.Inputs.Unpack(v, utils.HexString2Bytes(log.Data))
+	}
+	return
+}
+
+// EventID is the first topic of the logs of the event
+func (c *Contract) EventID(eventName string) string {
+	return c.abi.Events[eventName].ID().Hex()
 }
 
 func (c *Contract) Send(params *SendTxParams, amount *big.Int, functionName string, args ...interface{}) (resp string, err error) {
-	if _, ok := c.web3.privateKeyMap[strings.ToLower(params.FromAddress)]; !ok {
+	_, rawTx, err := c.Sign(params, amount, functionName, args...)
+	if err != nil {
+		return
+	}
+
+	return c.web3.Rpc.EthSendRawTransaction(rawTx)
+}
+
+// Sign builds and signs the transaction without sending it, rawTx can be sent later by EthSendRawTransaction.
+func (c *Contract) Sign(params *SendTxParams, amount *big.Int, functionName string, args ...interface{}) (txHash string, rawTx string, err error) {
+	signer, ok := c.web3.signerMap[strings.ToLower(params.FromAddress)]
+	if !ok {
 		err = utils.AddressNotExist
 		return
 	}
@@ -117,9 +269,20 @@ func (c *Contract) Send(params *SendTxParams, amount *big.Int, functionName stri
 		params.GasPrice,
 		data,
 	)
-	rawData, _ := utils.SignTx(c.web3.privateKeyMap[strings.ToLower(params.FromAddress)], os.Getenv("CHAIN_ID"), tx)
+	chainID, ok := new(big.Int).SetString(os.Getenv("CHAIN_ID"), 0)
+	if !ok {
+		panic("need chain id")
+	}
+	signedTx, err := signer.SignTx(tx, chainID)
+	if err != nil {
+		return
+	}
+	rawData, err := rlp.EncodeToBytes(signedTx)
+	if err != nil {
+		return
+	}
 
-	return c.web3.Rpc.EthSendRawTransaction(rawData)
+	return signedTx.Hash().Hex(), utils.Bytes2HexString(rawData), nil
 }
 
 func GetGasPriceGwei() (gasPriceInGwei int64) {